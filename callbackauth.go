@@ -0,0 +1,249 @@
+package silcomms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CallbackSignatureHeader carries the hex encoded HMAC-SHA256 signature of a callback.
+	// The signature is computed over the timestamp header, the nonce header and the body joined by a "."
+	CallbackSignatureHeader = "X-Signature"
+
+	// CallbackTimestampHeader carries the unix time in seconds at which a callback was sent
+	CallbackTimestampHeader = "X-Timestamp"
+
+	// CallbackNonceHeader carries a value that is unique to each callback
+	CallbackNonceHeader = "X-Nonce"
+
+	// CallbackTokenQueryParam carries the shared token when it is set on the callback URL instead of the Authorization header
+	CallbackTokenQueryParam = "token"
+)
+
+// defaultNonceTTL is how long nonces are remembered when the age of callbacks is not limited
+var defaultNonceTTL = 24 * time.Hour
+
+// errUnauthenticatedCallback is returned for callbacks that fail verification
+var errUnauthenticatedCallback = errors.New("unauthenticated callback")
+
+// CallbackOption customises how a callback handler verifies the callbacks it receives
+type CallbackOption func(*CallbackHandler)
+
+// WithHMACSecret requires callbacks to be signed with the provided secret, which must not be empty
+// The signature is read from the CallbackSignatureHeader and may be prefixed with "sha256=".
+// A signature alone does not expire, so a captured callback can be replayed indefinitely
+// unless WithMaxCallbackAge and WithNonceStore are also provided
+func WithHMACSecret(secret []byte) CallbackOption {
+	return func(h *CallbackHandler) {
+		h.secret = secret
+		h.verifySignature = true
+	}
+}
+
+// WithSharedToken requires callbacks to carry the provided token, which must not be empty
+// The token is read from a Bearer Authorization header or from the CallbackTokenQueryParam of the callback URL.
+// Like a signature, a token does not protect against replayed callbacks
+func WithSharedToken(token string) CallbackOption {
+	return func(h *CallbackHandler) {
+		h.token = token
+		h.verifyToken = true
+	}
+}
+
+// WithMaxCallbackAge rejects callbacks whose CallbackTimestampHeader is further than maxAge from the current time
+func WithMaxCallbackAge(maxAge time.Duration) CallbackOption {
+	return func(h *CallbackHandler) {
+		h.maxAge = maxAge
+	}
+}
+
+// WithNonceStore rejects callbacks whose CallbackNonceHeader has been seen before
+// Nonces are remembered for the maximum callback age when it is set and for 24 hours otherwise.
+// The nonce of a callback that the handler fails to process is forgotten so that the retried callback is accepted
+func WithNonceStore(store NonceStore) CallbackOption {
+	return func(h *CallbackHandler) {
+		h.nonces = store
+	}
+}
+
+// NonceStore remembers the nonces of the callbacks that have been received to protect against replayed callbacks
+type NonceStore interface {
+	// Seen records a nonce until expiresAt and reports whether it was already recorded.
+	// Recording and checking must be atomic so that concurrent replays are detected
+	Seen(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+
+	// Forget removes a recorded nonce so that a callback the handler failed to process can be retried with it
+	Forget(ctx context.Context, nonce string) error
+}
+
+// MemoryNonceStore is a NonceStore that keeps nonces in memory
+// It is only suitable when a single instance of the app receives callbacks
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// ensure the in memory nonce store can be used by a callback handler
+var _ NonceStore = (*MemoryNonceStore)(nil)
+
+// NewMemoryNonceStore initializes an empty in memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: map[string]time.Time{},
+	}
+}
+
+// Seen implements NonceStore and forgets expired nonces
+func (s *MemoryNonceStore) Seen(_ context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for n, expiry := range s.nonces {
+		if now.After(expiry) {
+			delete(s.nonces, n)
+		}
+	}
+
+	if _, ok := s.nonces[nonce]; ok {
+		return true, nil
+	}
+
+	s.nonces[nonce] = expiresAt
+
+	return false, nil
+}
+
+// validate checks that the configured verification cannot be bypassed with an empty secret or token
+func (h *CallbackHandler) validate() error {
+	if h.verifySignature && len(h.secret) == 0 {
+		return fmt.Errorf("callback HMAC secret must not be empty")
+	}
+
+	if h.verifyToken && h.token == "" {
+		return fmt.Errorf("callback shared token must not be empty")
+	}
+
+	return nil
+}
+
+// Forget implements NonceStore
+func (s *MemoryNonceStore) Forget(_ context.Context, nonce string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.nonces, nonce)
+
+	return nil
+}
+
+// verify checks the authenticity of a callback using the configured token, signature, age and nonce checks
+// The nonce is only recorded once the other checks pass so that forged callbacks cannot use up nonces
+func (h *CallbackHandler) verify(r *http.Request, body []byte, now time.Time) error {
+	if h.verifyToken && !h.validToken(r) {
+		return fmt.Errorf("%w: invalid token", errUnauthenticatedCallback)
+	}
+
+	timestamp := r.Header.Get(CallbackTimestampHeader)
+	nonce := r.Header.Get(CallbackNonceHeader)
+
+	if h.maxAge > 0 {
+		if err := checkTimestamp(timestamp, now, h.maxAge); err != nil {
+			return err
+		}
+	}
+
+	if h.verifySignature {
+		signature := strings.TrimPrefix(r.Header.Get(CallbackSignatureHeader), "sha256=")
+
+		if !hmac.Equal([]byte(signature), []byte(SignCallback(h.secret, timestamp, nonce, body))) {
+			return fmt.Errorf("%w: invalid signature", errUnauthenticatedCallback)
+		}
+	}
+
+	if h.nonces != nil {
+		if nonce == "" {
+			return fmt.Errorf("%w: missing nonce", errUnauthenticatedCallback)
+		}
+
+		ttl := defaultNonceTTL
+		if h.maxAge > 0 {
+			ttl = 2 * h.maxAge
+		}
+
+		seen, err := h.nonces.Seen(r.Context(), nonce, now.Add(ttl))
+		if err != nil {
+			return fmt.Errorf("failed to check callback nonce: %w", err)
+		}
+
+		if seen {
+			return fmt.Errorf("%w: replayed nonce", errUnauthenticatedCallback)
+		}
+	}
+
+	return nil
+}
+
+// forgetNonce lets SILCOMMS retry a callback that the handler failed to process with the same nonce
+func (h *CallbackHandler) forgetNonce(r *http.Request) {
+	if h.nonces == nil {
+		return
+	}
+
+	if err := h.nonces.Forget(r.Context(), r.Header.Get(CallbackNonceHeader)); err != nil {
+		logrus.Printf("failed to forget callback nonce, a retry of the callback will be rejected: %v", err)
+	}
+}
+
+// validToken checks the shared token in the Authorization header or the callback URL in constant time
+func (h *CallbackHandler) validToken(r *http.Request) bool {
+	token := r.URL.Query().Get(CallbackTokenQueryParam)
+
+	if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer ") {
+		token = strings.TrimPrefix(bearer, "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// checkTimestamp checks that a callback timestamp is within maxAge of the current time in either direction
+func checkTimestamp(timestamp string, now time.Time, maxAge time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", errUnauthenticatedCallback, timestamp)
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age < 0 {
+		age = -age
+	}
+
+	if age > maxAge {
+		return fmt.Errorf("%w: stale timestamp %q", errUnauthenticatedCallback, timestamp)
+	}
+
+	return nil
+}
+
+// SignCallback computes the hex encoded HMAC-SHA256 signature of a callback
+// It is the value expected in the CallbackSignatureHeader and can be used to sign callbacks in tests
+func SignCallback(secret []byte, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "." + nonce + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
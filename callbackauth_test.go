@@ -0,0 +1,302 @@
+package silcomms_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/savannahghi/silcomms"
+)
+
+// failingNonceStore is a nonce store that is unavailable
+type failingNonceStore struct{}
+
+func (failingNonceStore) Seen(context.Context, string, time.Time) (bool, error) {
+	return false, fmt.Errorf("nonce store unavailable")
+}
+
+func (failingNonceStore) Forget(context.Context, string) error {
+	return fmt.Errorf("nonce store unavailable")
+}
+
+func TestCallbackHandler_verification(t *testing.T) {
+	secret := []byte("callback-secret")
+	body := `{"guid": "9a1b2c3d", "msisdn": "+254722345678", "state": "DELIVERED"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	type callback struct {
+		query     string
+		token     string
+		timestamp string
+		nonce     string
+		signature string
+	}
+
+	tests := []struct {
+		name       string
+		opts       []silcomms.CallbackOption
+		callbacks  []callback
+		wantStatus []int
+	}{
+		{
+			name:       "happy case: valid signature",
+			opts:       []silcomms.CallbackOption{silcomms.WithHMACSecret(secret)},
+			callbacks:  []callback{{timestamp: now, nonce: "n1", signature: silcomms.SignCallback(secret, now, "n1", []byte(body))}},
+			wantStatus: []int{http.StatusOK},
+		},
+		{
+			name: "happy case: prefixed signature",
+			opts: []silcomms.CallbackOption{
+				silcomms.WithHMACSecret(secret),
+				silcomms.WithMaxCallbackAge(5 * time.Minute),
+				silcomms.WithNonceStore(silcomms.NewMemoryNonceStore()),
+			},
+			callbacks:  []callback{{timestamp: now, nonce: "n1", signature: "sha256=" + silcomms.SignCallback(secret, now, "n1", []byte(body))}},
+			wantStatus: []int{http.StatusOK},
+		},
+		{
+			name: "sad case: signed callback without a timestamp or nonce",
+			opts: []silcomms.CallbackOption{
+				silcomms.WithHMACSecret(secret),
+				silcomms.WithMaxCallbackAge(5 * time.Minute),
+				silcomms.WithNonceStore(silcomms.NewMemoryNonceStore()),
+			},
+			callbacks:  []callback{{signature: silcomms.SignCallback(secret, "", "", []byte(body))}},
+			wantStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:       "happy case: bearer token",
+			opts:       []silcomms.CallbackOption{silcomms.WithSharedToken("s3cret")},
+			callbacks:  []callback{{token: "s3cret"}},
+			wantStatus: []int{http.StatusOK},
+		},
+		{
+			name:       "happy case: token in callback url",
+			opts:       []silcomms.CallbackOption{silcomms.WithSharedToken("s3cret")},
+			callbacks:  []callback{{query: "?token=s3cret"}},
+			wantStatus: []int{http.StatusOK},
+		},
+		{
+			name:       "sad case: missing signature",
+			opts:       []silcomms.CallbackOption{silcomms.WithHMACSecret(secret)},
+			callbacks:  []callback{{timestamp: now}},
+			wantStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:       "sad case: signature with a different secret",
+			opts:       []silcomms.CallbackOption{silcomms.WithHMACSecret(secret)},
+			callbacks:  []callback{{timestamp: now, signature: silcomms.SignCallback([]byte("other"), now, "", []byte(body))}},
+			wantStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:       "sad case: tampered timestamp",
+			opts:       []silcomms.CallbackOption{silcomms.WithHMACSecret(secret)},
+			callbacks:  []callback{{timestamp: now, signature: silcomms.SignCallback(secret, stale, "", []byte(body))}},
+			wantStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:       "sad case: invalid token",
+			opts:       []silcomms.CallbackOption{silcomms.WithSharedToken("s3cret")},
+			callbacks:  []callback{{token: "guess"}, {query: "?token=guess"}, {}},
+			wantStatus: []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized},
+		},
+		{
+			name:       "sad case: stale or missing timestamp",
+			opts:       []silcomms.CallbackOption{silcomms.WithMaxCallbackAge(5 * time.Minute)},
+			callbacks:  []callback{{timestamp: now}, {timestamp: stale}, {timestamp: "yesterday"}, {}},
+			wantStatus: []int{http.StatusOK, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized},
+		},
+		{
+			name:       "sad case: replayed nonce",
+			opts:       []silcomms.CallbackOption{silcomms.WithNonceStore(silcomms.NewMemoryNonceStore())},
+			callbacks:  []callback{{nonce: "n1"}, {nonce: "n2"}, {nonce: "n1"}, {}},
+			wantStatus: []int{http.StatusOK, http.StatusOK, http.StatusUnauthorized, http.StatusUnauthorized},
+		},
+		{
+			name: "sad case: forged callback does not use up the nonce",
+			opts: []silcomms.CallbackOption{
+				silcomms.WithHMACSecret(secret),
+				silcomms.WithNonceStore(silcomms.NewMemoryNonceStore()),
+			},
+			callbacks: []callback{
+				{nonce: "n1", signature: "forged"},
+				{nonce: "n1", signature: silcomms.SignCallback(secret, "", "n1", []byte(body))},
+			},
+			wantStatus: []int{http.StatusUnauthorized, http.StatusOK},
+		},
+		{
+			name:       "sad case: nonce store failure",
+			opts:       []silcomms.CallbackOption{silcomms.WithNonceStore(failingNonceStore{})},
+			callbacks:  []callback{{nonce: "n1"}},
+			wantStatus: []int{http.StatusInternalServerError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &deliveryReportRecorder{}
+
+			server := httptest.NewServer(silcomms.MustNewCallbackHandler(recorder, tt.opts...))
+			defer server.Close()
+
+			delivered := 0
+
+			for i, cb := range tt.callbacks {
+				request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL+cb.query, strings.NewReader(body))
+				if err != nil {
+					t.Fatalf("failed to create request: %v", err)
+				}
+
+				if cb.token != "" {
+					request.Header.Set("Authorization", "Bearer "+cb.token)
+				}
+
+				request.Header.Set(silcomms.CallbackTimestampHeader, cb.timestamp)
+				request.Header.Set(silcomms.CallbackNonceHeader, cb.nonce)
+				request.Header.Set(silcomms.CallbackSignatureHeader, cb.signature)
+
+				response, err := server.Client().Do(request)
+				if err != nil {
+					t.Fatalf("failed to make request: %v", err)
+				}
+
+				response.Body.Close()
+
+				if response.StatusCode != tt.wantStatus[i] {
+					t.Errorf("CallbackHandler.ServeHTTP() callback %d status = %v, want %v", i, response.StatusCode, tt.wantStatus[i])
+				}
+
+				if response.StatusCode == http.StatusOK {
+					delivered++
+				}
+			}
+
+			if len(recorder.sms) != delivered {
+				t.Errorf("CallbackHandler.ServeHTTP() dispatched %d reports, want %d", len(recorder.sms), delivered)
+			}
+		})
+	}
+}
+
+func TestCallbackHandler_retryAfterHandlerFailure(t *testing.T) {
+	body := `{"guid": "9a1b2c3d", "msisdn": "+254722345678", "state": "DELIVERED"}`
+
+	recorder := &deliveryReportRecorder{err: fmt.Errorf("database unavailable")}
+
+	server := httptest.NewServer(silcomms.MustNewCallbackHandler(recorder, silcomms.WithNonceStore(silcomms.NewMemoryNonceStore())))
+	defer server.Close()
+
+	deliver := func() int {
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+
+		request.Header.Set(silcomms.CallbackNonceHeader, "n1")
+
+		response, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+
+		response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	if status := deliver(); status != http.StatusInternalServerError {
+		t.Fatalf("CallbackHandler.ServeHTTP() status = %v, want %v", status, http.StatusInternalServerError)
+	}
+
+	recorder.err = nil
+
+	if status := deliver(); status != http.StatusOK {
+		t.Fatalf("CallbackHandler.ServeHTTP() retried callback status = %v, want %v", status, http.StatusOK)
+	}
+
+	if status := deliver(); status != http.StatusUnauthorized {
+		t.Errorf("CallbackHandler.ServeHTTP() replayed callback status = %v, want %v", status, http.StatusUnauthorized)
+	}
+
+	if len(recorder.sms) != 2 {
+		t.Errorf("CallbackHandler.ServeHTTP() dispatched %d reports, want 2", len(recorder.sms))
+	}
+}
+
+func TestNewCallbackHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []silcomms.CallbackOption
+		wantErr bool
+	}{
+		{
+			name: "happy case: no verification",
+		},
+		{
+			name: "happy case: secret and token",
+			opts: []silcomms.CallbackOption{silcomms.WithHMACSecret([]byte("callback-secret")), silcomms.WithSharedToken("s3cret")},
+		},
+		{
+			name:    "sad case: nil secret",
+			opts:    []silcomms.CallbackOption{silcomms.WithHMACSecret(nil)},
+			wantErr: true,
+		},
+		{
+			name:    "sad case: empty secret",
+			opts:    []silcomms.CallbackOption{silcomms.WithHMACSecret([]byte{})},
+			wantErr: true,
+		},
+		{
+			name:    "sad case: empty token",
+			opts:    []silcomms.CallbackOption{silcomms.WithSharedToken("")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := silcomms.NewCallbackHandler(&deliveryReportRecorder{}, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCallbackHandler() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewCallbackHandler() = %v, want a handler %v", got, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryNonceStore_Seen(t *testing.T) {
+	ctx := context.Background()
+	store := silcomms.NewMemoryNonceStore()
+
+	if seen, _ := store.Seen(ctx, "n1", time.Now().Add(time.Minute)); seen {
+		t.Fatalf("MemoryNonceStore.Seen() = true for a new nonce")
+	}
+
+	if seen, _ := store.Seen(ctx, "n1", time.Now().Add(time.Minute)); !seen {
+		t.Fatalf("MemoryNonceStore.Seen() = false for a recorded nonce")
+	}
+
+	if seen, _ := store.Seen(ctx, "n2", time.Now().Add(-time.Minute)); seen {
+		t.Fatalf("MemoryNonceStore.Seen() = true for a new nonce")
+	}
+
+	if seen, _ := store.Seen(ctx, "n2", time.Now().Add(time.Minute)); seen {
+		t.Errorf("MemoryNonceStore.Seen() = true for an expired nonce")
+	}
+
+	if err := store.Forget(ctx, "n1"); err != nil {
+		t.Fatalf("MemoryNonceStore.Forget() error = %v", err)
+	}
+
+	if seen, _ := store.Seen(ctx, "n1", time.Now().Add(time.Minute)); seen {
+		t.Errorf("MemoryNonceStore.Seen() = true for a forgotten nonce")
+	}
+}
@@ -0,0 +1,238 @@
+package silcomms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxCallbackBodySize limits the size of the callback payloads read by the callback handler
+const maxCallbackBodySize = 1 << 20
+
+// DeliveryReportHandler receives the delivery reports that SILCOMMS sends to the app's sms_callback URL
+// An error returned by a handler method is reported to SILCOMMS with a 500 response so that the callback is retried
+type DeliveryReportHandler interface {
+	// HandleBulkSMSReport is called with the status of a bulk SMS
+	HandleBulkSMSReport(ctx context.Context, report *BulkSMSResponse) error
+
+	// HandleSMSReport is called with the delivery state of the SMS sent to a single recipient
+	HandleSMSReport(ctx context.Context, report *SMS) error
+}
+
+// CallbackHandler is a http.Handler that decodes the delivery reports sent to the app's sms_callback URL
+// and dispatches them to a DeliveryReportHandler
+type CallbackHandler struct {
+	handler DeliveryReportHandler
+
+	secret          []byte
+	verifySignature bool
+	token           string
+	verifyToken     bool
+	maxAge          time.Duration
+	nonces          NonceStore
+}
+
+// ensure the callback handler can be mounted on a http server
+var _ http.Handler = (*CallbackHandler)(nil)
+
+// NewCallbackHandler initializes a callback handler that dispatches delivery reports to the provided handler
+// Callbacks are not verified unless options such as WithHMACSecret or WithSharedToken are provided.
+// An empty secret or token is rejected instead of silently disabling verification
+func NewCallbackHandler(handler DeliveryReportHandler, opts ...CallbackOption) (*CallbackHandler, error) {
+	h := &CallbackHandler{
+		handler: handler,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if err := h.validate(); err != nil {
+		return nil, fmt.Errorf("failed to initialize callback handler: %w", err)
+	}
+
+	return h, nil
+}
+
+// MustNewCallbackHandler initializes a callback handler that dispatches delivery reports to the provided handler
+// It panics when the verification options are invalid
+func MustNewCallbackHandler(handler DeliveryReportHandler, opts ...CallbackOption) *CallbackHandler {
+	h, err := NewCallbackHandler(handler, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+// It responds with 401 to callbacks that fail verification, with 400 to payloads that are not valid delivery reports
+// and with 500 when the handler fails
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCallbackBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read callback payload: %v", err), http.StatusBadRequest)
+
+		return
+	}
+
+	err = h.verify(r, body, time.Now())
+	if errors.Is(err, errUnauthenticatedCallback) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	payload, err := readPayload(bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	err = h.dispatch(r.Context(), payload)
+	if err != nil {
+		status := http.StatusBadRequest
+
+		var handlerErr *callbackHandlerError
+		if errors.As(err, &handlerErr) {
+			status = http.StatusInternalServerError
+
+			h.forgetNonce(r)
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// dispatch decodes a delivery report and passes it to the handler
+// Bulk SMS reports are told apart from single recipient reports by their list of recipients
+func (h *CallbackHandler) dispatch(ctx context.Context, payload json.RawMessage) error {
+	var probe struct {
+		Recipients json.RawMessage `json:"recipients"`
+	}
+
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return fmt.Errorf("failed to decode delivery report: %w", err)
+	}
+
+	if probe.Recipients != nil {
+		report, err := decodeBulkSMSReport(payload)
+		if err != nil {
+			return err
+		}
+
+		return h.handle(func() error {
+			return h.handler.HandleBulkSMSReport(ctx, report)
+		})
+	}
+
+	report, err := decodeSMSReport(payload)
+	if err != nil {
+		return err
+	}
+
+	return h.handle(func() error {
+		return h.handler.HandleSMSReport(ctx, report)
+	})
+}
+
+// handle calls a handler method and marks its failure so that it is not reported as a bad payload
+func (h *CallbackHandler) handle(fn func() error) error {
+	if err := fn(); err != nil {
+		return &callbackHandlerError{err: err}
+	}
+
+	return nil
+}
+
+// callbackHandlerError is a failure of the DeliveryReportHandler to process a valid delivery report
+type callbackHandlerError struct {
+	err error
+}
+
+func (e *callbackHandlerError) Error() string {
+	return fmt.Sprintf("failed to handle delivery report: %v", e.err)
+}
+
+func (e *callbackHandlerError) Unwrap() error {
+	return e.err
+}
+
+// decodeBulkSMSReport decodes and validates the status of a bulk SMS
+func decodeBulkSMSReport(payload json.RawMessage) (*BulkSMSResponse, error) {
+	var report BulkSMSResponse
+
+	err := unmarshalJSON(payload, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bulk sms delivery report: %w", err)
+	}
+
+	if report.GUID == "" || report.State == "" {
+		return nil, fmt.Errorf("bulk sms delivery report must have a guid and state")
+	}
+
+	return &report, nil
+}
+
+// decodeSMSReport decodes and validates the delivery state of a single SMS
+func decodeSMSReport(payload json.RawMessage) (*SMS, error) {
+	var report SMS
+
+	err := unmarshalJSON(payload, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sms delivery report: %w", err)
+	}
+
+	if report.GUID == "" || report.Msisdn == "" || report.State == "" {
+		return nil, fmt.Errorf("sms delivery report must have a guid, msisdn and state")
+	}
+
+	return &report, nil
+}
+
+// readPayload reads a JSON object sent by SILCOMMS to one of the app's callback URLs
+// The object may be sent as is or wrapped in the data field of an API response
+func readPayload(r io.Reader) (json.RawMessage, error) {
+	var payload json.RawMessage
+
+	err := json.NewDecoder(r).Decode(&payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode callback payload: %w", err)
+	}
+
+	var envelope map[string]json.RawMessage
+
+	err = json.Unmarshal(payload, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("callback payload must be a JSON object: %w", err)
+	}
+
+	if data := bytes.TrimSpace(envelope["data"]); len(data) > 0 && data[0] == '{' {
+		return data, nil
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,130 @@
+package silcomms_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/savannahghi/silcomms"
+)
+
+// deliveryReportRecorder records the delivery reports it receives
+type deliveryReportRecorder struct {
+	bulk []*silcomms.BulkSMSResponse
+	sms  []*silcomms.SMS
+	err  error
+}
+
+func (r *deliveryReportRecorder) HandleBulkSMSReport(_ context.Context, report *silcomms.BulkSMSResponse) error {
+	r.bulk = append(r.bulk, report)
+
+	return r.err
+}
+
+func (r *deliveryReportRecorder) HandleSMSReport(_ context.Context, report *silcomms.SMS) error {
+	r.sms = append(r.sms, report)
+
+	return r.err
+}
+
+func TestCallbackHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		payload    string
+		handlerErr error
+		wantStatus int
+		wantBulk   int
+		wantSMS    int
+	}{
+		{
+			name:       "happy case: bulk sms report",
+			method:     http.MethodPost,
+			payload:    `{"guid": "4f8d2a1e", "sender": "SIL", "message": "Hello", "recipients": ["+254722345678"], "state": "COMPLETED"}`,
+			wantStatus: http.StatusOK,
+			wantBulk:   1,
+		},
+		{
+			name:       "happy case: sms report",
+			method:     http.MethodPost,
+			payload:    `{"guid": "9a1b2c3d", "msisdn": "+254722345678", "bulk": "4f8d2a1e", "state": "DELIVERED"}`,
+			wantStatus: http.StatusOK,
+			wantSMS:    1,
+		},
+		{
+			name:       "happy case: sms report wrapped in api response",
+			method:     http.MethodPost,
+			payload:    `{"status": "success", "message": "sms callback", "data": {"guid": "9a1b2c3d", "msisdn": "+254722345678", "state": "FAILED"}}`,
+			wantStatus: http.StatusOK,
+			wantSMS:    1,
+		},
+		{
+			name:       "sad case: unsupported method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "sad case: invalid json",
+			method:     http.MethodPost,
+			payload:    `{"guid": `,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "sad case: bulk sms report without state",
+			method:     http.MethodPost,
+			payload:    `{"guid": "4f8d2a1e", "recipients": ["+254722345678"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "sad case: sms report without msisdn",
+			method:     http.MethodPost,
+			payload:    `{"guid": "9a1b2c3d", "state": "DELIVERED"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "sad case: invalid sms report field type",
+			method:     http.MethodPost,
+			payload:    `{"guid": "9a1b2c3d", "msisdn": 254722345678, "state": "DELIVERED"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "sad case: handler failure",
+			method:     http.MethodPost,
+			payload:    `{"guid": "9a1b2c3d", "msisdn": "+254722345678", "state": "DELIVERED"}`,
+			handlerErr: fmt.Errorf("database unavailable"),
+			wantStatus: http.StatusInternalServerError,
+			wantSMS:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &deliveryReportRecorder{err: tt.handlerErr}
+
+			server := httptest.NewServer(silcomms.MustNewCallbackHandler(recorder))
+			defer server.Close()
+
+			request, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("CallbackHandler.ServeHTTP() status = %v, want %v", response.StatusCode, tt.wantStatus)
+			}
+
+			if len(recorder.bulk) != tt.wantBulk || len(recorder.sms) != tt.wantSMS {
+				t.Errorf("CallbackHandler.ServeHTTP() dispatched %d bulk and %d sms reports, want %d and %d",
+					len(recorder.bulk), len(recorder.sms), tt.wantBulk, tt.wantSMS)
+			}
+		})
+	}
+}
@@ -3,31 +3,24 @@ package silcomms
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/savannahghi/authutils"
-	"github.com/savannahghi/serverutils"
 	"github.com/sirupsen/logrus"
 )
 
-var (
-	// BaseURL represents the SIL-Comms base URL
-	BaseURL = serverutils.MustGetEnvVar("SIL_COMMS_BASE_URL")
-
-	// email is used for authentication against the SIL comms API
-	email = serverutils.MustGetEnvVar("SIL_COMMS_EMAIL")
-
-	// password is used for authentication against the SIL comms API
-	password = serverutils.MustGetEnvVar("SIL_COMMS_PASSWORD")
-
-	// accessTokenTimeout shows the access token expiry time.
-	// After the access token expires, one is required to obtain a new one
-	accessTokenTimeout = 59 * time.Minute
-)
+// ErrClientClosed is returned when a request is made using a client that has been closed
+var ErrClientClosed = errors.New("SIL comms client is closed")
 
 // AuthServerImpl defines the methods provided by
 // the auth server library
@@ -38,30 +31,58 @@ type AuthServerImpl interface {
 
 // It is the client used to make API request to sil communications API
 type client struct {
+	config     Config
 	authServer AuthServerImpl
 	client     *http.Client
 
+	// idempotency remembers the responses of send requests by idempotency key
+	idempotency *idempotencyCache
+
+	// mu guards the token state below which is shared between
+	// the background refresh routine and concurrent requests
+	mu sync.RWMutex
+
 	refreshToken string
 
 	accessToken       string
 	accessTokenTicker *time.Ticker
 
 	authFailed bool
+	closed     bool
+
+	// refreshing is closed when the token refresh in flight finishes. It is nil when no refresh is in flight
+	refreshing chan struct{}
+
+	// done is closed to stop the background routine
+	// stopped is closed once the background routine has exited
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
 }
 
 // newClient initializes a new SIL comms client instance
-func newClient(authServer AuthServerImpl) (*client, error) {
+func newClient(config Config, authServer AuthServerImpl) (*client, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	config = config.withDefaults()
+
 	s := &client{
+		config: config,
 		client: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout: config.HTTPTimeout,
 		},
 		authServer:   authServer,
+		idempotency:  newIdempotencyCache(config.IdempotencyTTL),
 		accessToken:  "",
 		refreshToken: "",
 		authFailed:   false,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
 	}
 
-	err := s.login()
+	err := s.login(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +94,8 @@ func newClient(authServer AuthServerImpl) (*client, error) {
 }
 
 // mustNewClient initializes a new SIL comms client instance
-func mustNewClient(authServer AuthServerImpl) *client {
-	client, err := newClient(authServer)
+func mustNewClient(config Config, authServer AuthServerImpl) *client {
+	client, err := newClient(config, authServer)
 	if err != nil {
 		panic(err)
 	}
@@ -83,39 +104,205 @@ func mustNewClient(authServer AuthServerImpl) *client {
 }
 
 // executed as a go routine to update access and refresh token
+// It runs until the client is closed
 func (s *client) background() {
-	for t := range s.accessTokenTicker.C {
-		logrus.Println("SIL Comms Access Token updated at: ", t)
+	defer close(s.stopped)
 
-		err := s.refreshAccessToken()
-		if err != nil {
-			s.authFailed = true
-		} else {
-			s.authFailed = false
+	s.mu.RLock()
+	ticker := s.accessTokenTicker
+	s.mu.RUnlock()
+
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case <-ticker.C:
+			err := s.singleRefresh(context.Background(), s.refreshAccessToken)
+
+			if err != nil {
+				logrus.Printf("failed to refresh SIL Comms access token, logging in again: %v", err)
+
+				err = s.reauthenticate()
+			}
+
+			if err == nil {
+				logrus.Println("SIL Comms Access Token updated at: ", time.Now())
+			}
+
+			s.setAuthFailed(err != nil)
+		}
+	}
+}
+
+// reauthenticate logs in again using the configured credentials when the refresh token is rejected
+// Failed attempts are retried with exponential backoff until the retry budget is exhausted or the client is closed
+func (s *client) reauthenticate() error {
+	backoff := s.config.LoginBackoff
+
+	var err error
+
+	for attempt := 1; attempt <= s.config.LoginRetries; attempt++ {
+		err = s.login(context.Background())
+		if err == nil {
+			return nil
+		}
+
+		if attempt == s.config.LoginRetries {
+			break
+		}
+
+		logrus.Printf("SIL Comms login attempt %d failed, retrying in %s: %v", attempt, backoff, err)
+
+		select {
+		case <-s.done:
+			return ErrClientClosed
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.config.MaxLoginBackoff {
+			backoff = s.config.MaxLoginBackoff
 		}
 	}
+
+	return fmt.Errorf("failed to login after %d attempts: %w", s.config.LoginRetries, err)
+}
+
+// close stops the token ticker and the background routine
+// It waits for the background routine to exit or for the context to be done
+func (s *client) close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.accessTokenTicker.Stop()
+		s.mu.Unlock()
+
+		close(s.done)
+	})
+
+	// prefer reporting a completed shutdown over an expired context
+	select {
+	case <-s.stopped:
+		return nil
+	default:
+	}
+
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isClosed returns true if the client has been closed
+func (s *client) isClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.closed
+}
+
+// setAuthFailed records whether the last background token refresh failed
+// After a failure the next attempt is scheduled after MaxLoginBackoff instead of a full refresh interval
+func (s *client) setAuthFailed(failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.authFailed = failed
+
+	if failed && !s.closed {
+		s.accessTokenTicker.Reset(s.config.MaxLoginBackoff)
+	}
+}
+
+// authState returns a consistent snapshot of the access token and the auth failure flag
+func (s *client) authState() (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.accessToken, s.authFailed
 }
 
 // setAccessToken sets the access token and updates the ticker timer
 func (s *client) setRefreshAndAccessToken(token *TokenResponse) {
+	interval := s.refreshInterval(token, time.Now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.accessToken = token.Access
 
 	s.refreshToken = token.Refresh
 	if s.accessTokenTicker != nil {
-		s.accessTokenTicker.Reset(accessTokenTimeout)
+		s.accessTokenTicker.Reset(interval)
 	} else {
-		s.accessTokenTicker = time.NewTicker(accessTokenTimeout)
+		s.accessTokenTicker = time.NewTicker(interval)
 	}
 }
 
+// refreshInterval returns how long to wait before refreshing the provided token
+// The token is refreshed after the configured fraction of its lifetime has elapsed.
+// The configured access token timeout is used when the lifetime is unknown
+func (s *client) refreshInterval(token *TokenResponse, now time.Time) time.Duration {
+	lifetime, ok := tokenLifetime(token, now)
+	if !ok {
+		return s.config.AccessTokenTimeout
+	}
+
+	interval := time.Duration(float64(lifetime) * s.config.TokenRefreshFraction)
+	if interval < minRefreshInterval {
+		return minRefreshInterval
+	}
+
+	return interval
+}
+
+// tokenLifetime returns how long an access token is valid for
+// The expires in value returned by the auth server is preferred over the JWT exp claim
+func tokenLifetime(token *TokenResponse, now time.Time) (time.Duration, bool) {
+	if token.ExpiresIn > 0 {
+		return time.Duration(token.ExpiresIn) * time.Second, true
+	}
+
+	expiry, ok := jwtExpiry(token.Access)
+	if !ok {
+		return 0, false
+	}
+
+	return expiry.Sub(now), true
+}
+
+// jwtExpiry reads the exp claim of a JWT access token without verifying its signature
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(claims.Exp), 0), true
+}
+
 // login uses the provided credentials to login to the authserver backend
 // It obtains the necessary tokens required to make authenticated requests
-func (s *client) login() error {
-	ctx := context.Background()
-
+func (s *client) login(ctx context.Context) error {
 	loginInput := authutils.LoginUserPayload{
-		Email:    email,
-		Password: password,
+		Email:    s.config.Email,
+		Password: s.config.Password,
 	}
 
 	resp, err := s.authServer.LoginUser(ctx, &loginInput)
@@ -124,8 +311,9 @@ func (s *client) login() error {
 	}
 
 	tokens := TokenResponse{
-		Access:  resp.AccessToken,
-		Refresh: resp.RefreshToken,
+		Access:    resp.AccessToken,
+		Refresh:   resp.RefreshToken,
+		ExpiresIn: resp.ExpiresIn,
 	}
 
 	s.setRefreshAndAccessToken(&tokens)
@@ -135,17 +323,20 @@ func (s *client) login() error {
 
 // refreshAccessToken makes a request to get
 // new access and refresh tokens
-func (s *client) refreshAccessToken() error {
-	ctx := context.Background()
+func (s *client) refreshAccessToken(ctx context.Context) error {
+	s.mu.RLock()
+	refreshToken := s.refreshToken
+	s.mu.RUnlock()
 
-	resp, err := s.authServer.RefreshToken(ctx, s.refreshToken)
+	resp, err := s.authServer.RefreshToken(ctx, refreshToken)
 	if err != nil {
 		return err
 	}
 
 	tokens := TokenResponse{
-		Access:  resp.AccessToken,
-		Refresh: resp.RefreshToken,
+		Access:    resp.AccessToken,
+		Refresh:   resp.RefreshToken,
+		ExpiresIn: resp.ExpiresIn,
 	}
 
 	s.setRefreshAndAccessToken(&tokens)
@@ -153,50 +344,213 @@ func (s *client) refreshAccessToken() error {
 	return nil
 }
 
+// refreshExpiredToken obtains a new access token after the API rejected the provided one
+// Concurrent callers are coalesced so that only one refresh is in flight at a time.
+// Callers that waited on an in-flight refresh reuse the token it obtained.
+// Waiting and refreshing stop when the context is done
+func (s *client) refreshExpiredToken(ctx context.Context, expired string) (string, error) {
+	err := s.singleRefresh(ctx, func(ctx context.Context) error {
+		if current, _ := s.authState(); current != expired {
+			return nil
+		}
+
+		if err := s.refreshAccessToken(ctx); err != nil {
+			return s.login(ctx)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	current, _ := s.authState()
+
+	return current, nil
+}
+
+// singleRefresh runs refresh unless a token refresh is already in flight.
+// Callers that find a refresh in flight wait for it to finish, or for the context to be done,
+// and then try again so that refresh can check whether the token still needs refreshing
+func (s *client) singleRefresh(ctx context.Context, refresh func(context.Context) error) error {
+	for {
+		s.mu.Lock()
+		inFlight := s.refreshing
+
+		if inFlight == nil {
+			done := make(chan struct{})
+			s.refreshing = done
+			s.mu.Unlock()
+
+			err := refresh(ctx)
+
+			s.mu.Lock()
+			s.refreshing = nil
+			s.mu.Unlock()
+
+			close(done)
+
+			return err
+		}
+
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-inFlight:
+		}
+	}
+}
+
+// requestOption customises a single request made using MakeRequest
+type requestOption func(*http.Request)
+
+// withHeader sets a header on the request
+func withHeader(key, value string) requestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
 // MakeRequest performs a HTTP request to the provided path and parameters
-func (s *client) MakeRequest(ctx context.Context, method, path string, queryParams map[string]string, body interface{}, authorised bool) (*http.Response, error) {
+// Any HTTP method can be used and the body is sent as JSON when provided.
+// An authorised request that is rejected with a 401 is replayed once after refreshing the access token
+func (s *client) MakeRequest(ctx context.Context, method, path string, queryParams map[string]string, body interface{}, authorised bool, opts ...requestOption) (*http.Response, error) {
+	if s.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	accessToken, authFailed := s.authState()
+
 	// background refresh failed and the tokens are not valid
-	if s.authFailed {
+	if authFailed {
 		return nil, fmt.Errorf("invalid credentials, cannot make request please update")
 	}
 
-	urlPath := fmt.Sprintf("%s%s", BaseURL, path)
+	if !validMethod(method) {
+		return nil, fmt.Errorf("s.MakeRequest() invalid http method: %q", method)
+	}
 
-	var request *http.Request
+	var payload []byte
 
-	switch method {
-	case http.MethodGet:
-		req, err := http.NewRequestWithContext(ctx, method, urlPath, nil)
+	if body != nil {
+		encoded, err := json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
 
-		request = req
+		payload = encoded
+	}
 
-	case http.MethodPost:
-		encoded, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
+	response, err := s.doWithRetry(ctx, method, path, queryParams, payload, authorised, accessToken, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !authorised || response.StatusCode != http.StatusUnauthorized {
+		return response, nil
+	}
+
+	// the access token expired before the scheduled refresh
+	response.Body.Close()
+
+	accessToken, err = s.refreshExpiredToken(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh expired access token: %w", err)
+	}
+
+	return s.doWithRetry(ctx, method, path, queryParams, payload, authorised, accessToken, opts)
+}
+
+// doWithRetry sends a request retrying transient failures as allowed by the configured retry policy
+func (s *client) doWithRetry(ctx context.Context, method, path string, queryParams map[string]string, payload []byte, authorised bool, accessToken string, opts []requestOption) (*http.Response, error) {
+	sent := false
+
+	for attempt := 1; ; attempt++ {
+		response, err := s.do(ctx, method, path, queryParams, payload, authorised, accessToken, opts)
+
+		var sentErr *requestSentError
+		if response != nil || errors.As(err, &sentErr) {
+			sent = true
 		}
 
-		payload := bytes.NewBuffer(encoded)
+		wait, retry := s.config.RetryPolicy.Retry(attempt, method, response, err)
+		if !retry {
+			return response, err
+		}
 
-		req, err := http.NewRequestWithContext(ctx, method, urlPath, payload)
-		if err != nil {
-			return nil, err
+		if response != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
 		}
 
-		request = req
+		logrus.Printf("SIL Comms %s %s attempt %d failed, retrying in %s", method, path, attempt, wait)
 
-	default:
-		return nil, fmt.Errorf("s.MakeRequest() unsupported http method: %s", method)
+		select {
+		case <-ctx.Done():
+			if !sent {
+				return nil, ctx.Err()
+			}
+
+			// an earlier attempt was sent so the API may have acted on it
+			return nil, &requestSentError{err: ctx.Err()}
+		case <-time.After(wait):
+		}
+	}
+}
+
+// resolveURL joins a path to the base URL
+// Absolute URLs such as pagination links are only followed when they point to the SIL comms API
+// so that the access token is never sent to another host
+func (s *client) resolveURL(path string) (string, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return fmt.Sprintf("%s%s", s.config.BaseURL, path), nil
+	}
+
+	base, err := url.Parse(s.config.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid SIL comms base URL: %w", err)
+	}
+
+	target, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid request URL: %w", err)
+	}
+
+	if target.Host != base.Host {
+		return "", fmt.Errorf("refusing to make request to %s outside the SIL comms API", target.Host)
+	}
+
+	// pagination links may use http when the API is behind a proxy
+	target.Scheme = base.Scheme
+
+	return target.String(), nil
+}
+
+// do builds and sends a single HTTP request
+// The payload is the encoded JSON body which allows the same request to be sent more than once
+func (s *client) do(ctx context.Context, method, path string, queryParams map[string]string, payload []byte, authorised bool, accessToken string, opts []requestOption) (*http.Response, error) {
+	urlPath, err := s.resolveURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, urlPath, body)
+	if err != nil {
+		return nil, err
 	}
 
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Content-Type", "application/json")
 
 	if authorised {
-		request.Header.Set("Authorization", fmt.Sprintf("X-Bearer %s", s.accessToken))
+		request.Header.Set("Authorization", fmt.Sprintf("X-Bearer %s", accessToken))
 	}
 
 	if queryParams != nil {
@@ -209,5 +563,63 @@ func (s *client) MakeRequest(ctx context.Context, method, path string, queryPara
 		request.URL.RawQuery = q.Encode()
 	}
 
-	return s.client.Do(request)
+	for _, opt := range opts {
+		opt(request)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		if !mayHaveBeenSent(err) {
+			return nil, err
+		}
+
+		return nil, &requestSentError{err: err}
+	}
+
+	return response, nil
+}
+
+// mayHaveBeenSent reports whether a failed request may have reached the API
+// Failures to resolve or connect to the API happen before anything is written so the request was not sent
+func mayHaveBeenSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+
+	return true
+}
+
+// validMethod reports whether a method is a valid HTTP method token as defined by RFC 7230
+func validMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+
+	for _, r := range method {
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", r) && !('0' <= r && r <= '9') && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestSentError marks a failure that happened after a request was sent, such as a timeout,
+// where the API may or may not have acted on the request
+type requestSentError struct {
+	err error
+}
+
+func (e *requestSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestSentError) Unwrap() error {
+	return e.err
 }
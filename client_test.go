@@ -2,9 +2,19 @@ package silcomms
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit"
 	"github.com/jarcoal/httpmock"
@@ -47,6 +57,12 @@ func (oc AuthServerServiceMock) RefreshToken(ctx context.Context, refreshToken s
 
 var authServer = NewAuthServerServiceMock()
 
+var testConfig = Config{
+	BaseURL:  "https://comms.example.com",
+	Email:    gofakeit.Email(),
+	Password: gofakeit.Password(true, true, true, true, false, 12),
+}
+
 func TestSILclient_MakeRequest(t *testing.T) {
 	type args struct {
 		ctx         context.Context
@@ -77,10 +93,10 @@ func TestSILclient_MakeRequest(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "sad case: make unsupported protocol request",
+			name: "sad case: make request with an invalid method",
 			args: args{
 				ctx:    context.Background(),
-				method: http.MethodOptions,
+				method: "GET /",
 				path:   "/v1/sms/bulk/",
 				queryParams: map[string]string{
 					"app": gofakeit.UUID(),
@@ -97,11 +113,11 @@ func TestSILclient_MakeRequest(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			s := mustNewClient(authServer)
+			s := mustNewClient(testConfig, authServer)
 
 			if tt.name == "happy case: make authenticated POST request" {
 				httpmock.RegisterResponder(http.MethodPost, "/v1/sms/bulk/", func(_ *http.Request) (*http.Response, error) {
-					resp := APIResponse{
+					resp := APIResponse[BulkSMSResponse]{
 						Status:  StatusSuccess,
 						Message: "success",
 						Data: BulkSMSResponse{
@@ -111,8 +127,6 @@ func TestSILclient_MakeRequest(t *testing.T) {
 							Recipients: []string{},
 							State:      "",
 							SMS:        []string{},
-							Created:    "",
-							Updated:    "",
 						},
 					}
 
@@ -122,7 +136,7 @@ func TestSILclient_MakeRequest(t *testing.T) {
 
 			if tt.name == "happy case: make authenticated GET request" {
 				httpmock.RegisterResponder(http.MethodGet, "/v1/sms/bulk/", func(_ *http.Request) (*http.Response, error) {
-					resp := APIResponse{
+					resp := APIResponse[[]BulkSMSResponse]{
 						Status:  StatusSuccess,
 						Message: "success",
 						Data: []BulkSMSResponse{
@@ -133,8 +147,6 @@ func TestSILclient_MakeRequest(t *testing.T) {
 								Recipients: []string{},
 								State:      "",
 								SMS:        []string{},
-								Created:    "",
-								Updated:    "",
 							},
 						},
 					}
@@ -171,7 +183,7 @@ func Test_client_refreshAccessToken(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			s := mustNewClient(authServer)
+			s := mustNewClient(testConfig, authServer)
 
 			if tt.name == "sad case: error occurs" {
 				authServer.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:all
@@ -179,7 +191,7 @@ func Test_client_refreshAccessToken(t *testing.T) {
 				}
 			}
 
-			if err := s.refreshAccessToken(); (err != nil) != tt.wantErr {
+			if err := s.refreshAccessToken(context.Background()); (err != nil) != tt.wantErr {
 				t.Errorf("client.refreshAccessToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -205,7 +217,7 @@ func Test_client_login(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			s, err := newClient(authServer)
+			s, err := newClient(testConfig, authServer)
 			if err != nil {
 				t.Errorf("failed to initialize: %v", err)
 				return
@@ -217,9 +229,665 @@ func Test_client_login(t *testing.T) {
 				}
 			}
 
-			if err := s.login(); (err != nil) != tt.wantErr {
+			if err := s.login(context.Background()); (err != nil) != tt.wantErr {
 				t.Errorf("client.login() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_client_concurrentRefreshAndRequests(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var issued int64
+
+	server := NewAuthServerServiceMock()
+	server.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:revive
+		n := atomic.AddInt64(&issued, 1)
+
+		return &authutils.OAUTHResponse{
+			AccessToken:  fmt.Sprintf("access-%d", n),
+			RefreshToken: fmt.Sprintf("refresh-%d", n),
+		}, nil
+	}
+
+	// a short token timeout keeps the background routine refreshing while requests are in flight
+	config := testConfig
+	config.AccessTokenTimeout = time.Millisecond
+
+	s := mustNewClient(config, server)
+	defer s.close(context.Background()) //nolint:errcheck
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), func(r *http.Request) (*http.Response, error) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "X-Bearer access") {
+			return httpmock.NewStringResponse(http.StatusUnauthorized, ""), nil
+		}
+
+		return httpmock.NewJsonResponse(http.StatusOK, APIResponse[any]{Status: StatusSuccess})
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			if err := s.refreshAccessToken(context.Background()); err != nil {
+				t.Errorf("client.refreshAccessToken() error = %v", err)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := s.MakeRequest(context.Background(), http.MethodGet, "/v1/sms/bulk/", nil, nil, true)
+			if err != nil {
+				t.Errorf("client.MakeRequest() error = %v", err)
+				return
+			}
+
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("client.MakeRequest() status = %v, want %v", resp.StatusCode, http.StatusOK)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func Test_client_close(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+	}{
+		{
+			name:    "happy case: close client",
+			ctx:     context.Background(),
+			wantErr: false,
+		},
+		{
+			name:    "happy case: close client with a cancelled context after shutdown",
+			ctx:     cancelled,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := mustNewClient(testConfig, NewAuthServerServiceMock())
+
+			if err := s.close(context.Background()); err != nil {
+				t.Errorf("client.close() error = %v", err)
+				return
+			}
+
+			if err := s.close(tt.ctx); (err != nil) != tt.wantErr {
+				t.Errorf("client.close() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			_, err := s.MakeRequest(context.Background(), http.MethodGet, "/v1/sms/bulk/", nil, nil, true) //nolint: bodyclose
+			if !errors.Is(err, ErrClientClosed) {
+				t.Errorf("client.MakeRequest() error = %v, want %v", err, ErrClientClosed)
+			}
+		})
+	}
+}
+
+func Test_client_reauthenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		loginFailures int64
+		wantErr       bool
+	}{
+		{
+			name:          "happy case: login on first attempt",
+			loginFailures: 0,
+			wantErr:       false,
+		},
+		{
+			name:          "happy case: login after transient failures",
+			loginFailures: 2,
+			wantErr:       false,
+		},
+		{
+			name:          "sad case: retry budget exhausted",
+			loginFailures: 3,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig
+			config.LoginRetries = 3
+			config.LoginBackoff = time.Millisecond
+
+			s := mustNewClient(config, NewAuthServerServiceMock())
+			defer s.close(context.Background()) //nolint:errcheck
+
+			var attempts int64
+
+			server := NewAuthServerServiceMock()
+			server.MockLoginUserFn = func(ctx context.Context, input *authutils.LoginUserPayload) (*authutils.OAUTHResponse, error) { //nolint:revive
+				if atomic.AddInt64(&attempts, 1) <= tt.loginFailures {
+					return nil, fmt.Errorf("error")
+				}
+
+				return &authutils.OAUTHResponse{
+					AccessToken:  "access",
+					RefreshToken: "refresh",
+				}, nil
+			}
+			s.authServer = server
+
+			if err := s.reauthenticate(); (err != nil) != tt.wantErr {
+				t.Errorf("client.reauthenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_client_backgroundRecoversFromRejectedRefreshToken(t *testing.T) {
+	var loginAttempts int64
+
+	server := NewAuthServerServiceMock()
+	server.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:revive
+		return nil, fmt.Errorf("refresh token rejected")
+	}
+	server.MockLoginUserFn = func(ctx context.Context, input *authutils.LoginUserPayload) (*authutils.OAUTHResponse, error) { //nolint:revive
+		// the first background re-login attempt fails and is retried
+		n := atomic.AddInt64(&loginAttempts, 1)
+		if n == 2 {
+			return nil, fmt.Errorf("auth server unavailable")
+		}
+
+		return &authutils.OAUTHResponse{
+			AccessToken:  fmt.Sprintf("access-%d", n),
+			RefreshToken: "refresh",
+		}, nil
+	}
+
+	config := testConfig
+	config.AccessTokenTimeout = 5 * time.Millisecond
+	config.LoginRetries = 3
+	config.LoginBackoff = time.Millisecond
+
+	s := mustNewClient(config, server)
+	defer s.close(context.Background()) //nolint:errcheck
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for atomic.LoadInt64(&loginAttempts) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("client did not log in again after the refresh token was rejected")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, authFailed := s.authState(); authFailed {
+		t.Errorf("client.authFailed = %v, want %v", authFailed, false)
+	}
+}
+
+func Test_client_backgroundRecoversAfterLoginRetriesAreExhausted(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var down int32
+
+	server := NewAuthServerServiceMock()
+	server.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:revive
+		return nil, fmt.Errorf("refresh token rejected")
+	}
+	server.MockLoginUserFn = func(ctx context.Context, input *authutils.LoginUserPayload) (*authutils.OAUTHResponse, error) { //nolint:revive
+		if atomic.LoadInt32(&down) == 1 {
+			return nil, fmt.Errorf("auth server unavailable")
+		}
+
+		return &authutils.OAUTHResponse{
+			AccessToken:  "access",
+			RefreshToken: "refresh",
+		}, nil
+	}
+
+	config := testConfig
+	config.AccessTokenTimeout = time.Second
+	config.LoginRetries = 2
+	config.LoginBackoff = time.Millisecond
+	config.MaxLoginBackoff = 10 * time.Millisecond
+
+	s := mustNewClient(config, server)
+	defer s.close(context.Background()) //nolint:errcheck
+
+	atomic.StoreInt32(&down, 1)
+
+	waitFor := func(failed bool, within time.Duration) {
+		deadline := time.Now().Add(within)
+
+		for {
+			if _, authFailed := s.authState(); authFailed == failed {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("client.authFailed did not become %v within %s", failed, within)
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitFor(true, 5*time.Second)
+
+	atomic.StoreInt32(&down, 0)
+
+	// recovery must not wait for the full one second refresh interval
+	waitFor(false, 500*time.Millisecond)
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), httpmock.NewStringResponder(http.StatusOK, "{}"))
+
+	response, err := s.MakeRequest(context.Background(), http.MethodGet, "/v1/sms/bulk/", nil, nil, true)
+	if err != nil {
+		t.Fatalf("client.MakeRequest() error = %v", err)
+	}
+
+	response.Body.Close()
+}
+
+func Test_client_MakeRequestReplaysUnauthorisedRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var refreshes int64
+
+	server := NewAuthServerServiceMock()
+	server.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:revive
+		atomic.AddInt64(&refreshes, 1)
+
+		// widen the window in which other requests hit a 401
+		time.Sleep(10 * time.Millisecond)
+
+		return &authutils.OAUTHResponse{
+			AccessToken:  "renewed",
+			RefreshToken: "refresh",
+		}, nil
+	}
+
+	s := mustNewClient(testConfig, server)
+	defer s.close(context.Background()) //nolint:errcheck
+
+	body := map[string]string{"message": "This is a test"}
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("Authorization") != "X-Bearer renewed" {
+			return httpmock.NewStringResponse(http.StatusUnauthorized, ""), nil
+		}
+
+		var got map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil || got["message"] != body["message"] {
+			return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+		}
+
+		return httpmock.NewJsonResponse(http.StatusAccepted, APIResponse[any]{Status: StatusSuccess})
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := s.MakeRequest(context.Background(), http.MethodPost, "/v1/sms/bulk/", nil, body, true)
+			if err != nil {
+				t.Errorf("client.MakeRequest() error = %v", err)
+				return
+			}
+
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusAccepted {
+				t.Errorf("client.MakeRequest() status = %v, want %v", resp.StatusCode, http.StatusAccepted)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&refreshes); got != 1 {
+		t.Errorf("client.MakeRequest() refreshed the token %d times, want 1", got)
+	}
+}
+
+func Test_client_MakeRequestUnauthorisedRefreshFails(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	s := mustNewClient(testConfig, NewAuthServerServiceMock())
+	defer s.close(context.Background()) //nolint:errcheck
+
+	server := NewAuthServerServiceMock()
+	server.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:revive
+		return nil, fmt.Errorf("error")
+	}
+	server.MockLoginUserFn = func(ctx context.Context, input *authutils.LoginUserPayload) (*authutils.OAUTHResponse, error) { //nolint:revive
+		return nil, fmt.Errorf("error")
+	}
+	s.authServer = server
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), httpmock.NewStringResponder(http.StatusUnauthorized, ""))
+
+	_, err := s.MakeRequest(context.Background(), http.MethodGet, "/v1/sms/bulk/", nil, nil, true) //nolint: bodyclose
+	if err == nil {
+		t.Errorf("client.MakeRequest() expected an error when the token cannot be refreshed")
+	}
+}
+
+func Test_client_MakeRequestUnauthorisedRefreshCancelled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	s := mustNewClient(testConfig, NewAuthServerServiceMock())
+	defer s.close(context.Background()) //nolint:errcheck
+
+	// the auth server hangs until the caller gives up
+	server := NewAuthServerServiceMock()
+	server.MockRefreshTokenFn = func(ctx context.Context, refreshToken string) (*authutils.OAUTHResponse, error) { //nolint:revive
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+	server.MockLoginUserFn = func(ctx context.Context, input *authutils.LoginUserPayload) (*authutils.OAUTHResponse, error) { //nolint:revive
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+	s.authServer = server
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), httpmock.NewStringResponder(http.StatusUnauthorized, ""))
+
+	request := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := s.MakeRequest(ctx, http.MethodGet, "/v1/sms/bulk/", nil, nil, true) //nolint: bodyclose
+
+		return err
+	}
+
+	// the request refreshes the token itself
+	if err := request(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("client.MakeRequest() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the request waits on a refresh that another caller started
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go s.singleRefresh(context.Background(), func(context.Context) error { //nolint:errcheck
+		close(started)
+		<-release
+
+		return nil
+	})
+
+	<-started
+
+	if err := request(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("client.MakeRequest() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+}
+
+func Test_client_refreshInterval(t *testing.T) {
+	now := time.Now()
+
+	jwt := func(claims string) string {
+		return fmt.Sprintf("header.%s.signature", base64.RawURLEncoding.EncodeToString([]byte(claims)))
+	}
+
+	s := &client{config: testConfig.withDefaults()}
+	s.config.TokenRefreshFraction = 0.5
+
+	tests := []struct {
+		name  string
+		token *TokenResponse
+		want  time.Duration
+	}{
+		{
+			name:  "happy case: use expires in",
+			token: &TokenResponse{Access: "access", ExpiresIn: 3600},
+			want:  30 * time.Minute,
+		},
+		{
+			name:  "happy case: use the JWT exp claim",
+			token: &TokenResponse{Access: jwt(fmt.Sprintf(`{"exp": %d}`, now.Add(20*time.Minute).Unix()))},
+			want:  10 * time.Minute,
+		},
+		{
+			name:  "happy case: expired JWT is refreshed after the minimum interval",
+			token: &TokenResponse{Access: jwt(fmt.Sprintf(`{"exp": %d}`, now.Add(-time.Minute).Unix()))},
+			want:  minRefreshInterval,
+		},
+		{
+			name:  "happy case: fall back to the access token timeout for opaque tokens",
+			token: &TokenResponse{Access: "access"},
+			want:  defaultAccessTokenTimeout,
+		},
+		{
+			name:  "happy case: fall back to the access token timeout for JWTs without exp",
+			token: &TokenResponse{Access: jwt(`{"sub": "user"}`)},
+			want:  defaultAccessTokenTimeout,
+		},
+		{
+			name:  "sad case: fall back to the access token timeout for malformed JWTs",
+			token: &TokenResponse{Access: "header.!!!.signature"},
+			want:  defaultAccessTokenTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.refreshInterval(tt.token, now)
+
+			// the JWT exp claim is truncated to seconds
+			if diff := got - tt.want; diff > time.Second || diff < -time.Second {
+				t.Errorf("client.refreshInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_MakeRequestMethods(t *testing.T) {
+	type args struct {
+		method      string
+		queryParams map[string]string
+		body        interface{}
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		wantBody string
+	}{
+		{
+			name: "happy case: make PUT request",
+			args: args{
+				method: http.MethodPut,
+				body:   map[string]string{"offer": "01262626626"},
+			},
+			wantBody: `{"offer":"01262626626"}`,
+		},
+		{
+			name: "happy case: make PATCH request with query params",
+			args: args{
+				method:      http.MethodPatch,
+				queryParams: map[string]string{"msisdn": "+254722345678"},
+				body:        map[string]string{"deactivation_type": "USER_INITIATED"},
+			},
+			wantBody: `{"deactivation_type":"USER_INITIATED"}`,
+		},
+		{
+			name: "happy case: make DELETE request without a body",
+			args: args{
+				method: http.MethodDelete,
+			},
+			wantBody: "",
+		},
+		{
+			name: "happy case: make DELETE request with a body",
+			args: args{
+				method: http.MethodDelete,
+				body:   map[string]string{"reason": "STOP"},
+			},
+			wantBody: `{"reason":"STOP"}`,
+		},
+		{
+			name: "happy case: make HEAD request",
+			args: args{
+				method: http.MethodHead,
+			},
+			wantBody: "",
+		},
+		{
+			name: "happy case: make OPTIONS request",
+			args: args{
+				method: http.MethodOptions,
+			},
+			wantBody: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			s := mustNewClient(testConfig, NewAuthServerServiceMock())
+			defer s.close(context.Background()) //nolint:errcheck
+
+			var gotBody, gotQuery string
+
+			httpmock.RegisterResponder(tt.args.method, fmt.Sprintf("%s/v1/sms/subscriptions/", testConfig.BaseURL), func(r *http.Request) (*http.Response, error) {
+				if r.Body != nil {
+					body, err := io.ReadAll(r.Body)
+					if err != nil {
+						return nil, err
+					}
+
+					gotBody = string(body)
+				}
+
+				gotQuery = r.URL.Query().Get("msisdn")
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			})
+
+			resp, err := s.MakeRequest(context.Background(), tt.args.method, "/v1/sms/subscriptions/", tt.args.queryParams, tt.args.body, true)
+			if err != nil {
+				t.Errorf("client.MakeRequest() error = %v", err)
+				return
+			}
+
+			defer resp.Body.Close()
+
+			if gotBody != tt.wantBody {
+				t.Errorf("client.MakeRequest() sent body = %v, want %v", gotBody, tt.wantBody)
+			}
+
+			if gotQuery != tt.args.queryParams["msisdn"] {
+				t.Errorf("client.MakeRequest() sent msisdn query param = %v, want %v", gotQuery, tt.args.queryParams["msisdn"])
+			}
+		})
+	}
+}
+
+func Test_client_resolveURL(t *testing.T) {
+	s := &client{config: testConfig}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "happy case: relative path",
+			path: "/v1/sms/subscriptions/",
+			want: "https://comms.example.com/v1/sms/subscriptions/",
+		},
+		{
+			name: "happy case: pagination link",
+			path: "https://comms.example.com/v1/sms/subscriptions/?page=2",
+			want: "https://comms.example.com/v1/sms/subscriptions/?page=2",
+		},
+		{
+			name: "happy case: pagination link behind a proxy",
+			path: "http://comms.example.com/v1/sms/subscriptions/?page=2",
+			want: "https://comms.example.com/v1/sms/subscriptions/?page=2",
+		},
+		{
+			name:    "sad case: link to another host",
+			path:    "https://attacker.example.com/v1/sms/subscriptions/?page=2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.resolveURL(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.resolveURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("client.resolveURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mayHaveBeenSent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "happy case: timeout after sending",
+			err:  &url.Error{Op: "Post", URL: "https://comms.example.com", Err: context.DeadlineExceeded},
+			want: true,
+		},
+		{
+			name: "happy case: connection reset while reading the response",
+			err:  &url.Error{Op: "Post", URL: "https://comms.example.com", Err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}},
+			want: true,
+		},
+		{
+			name: "sad case: connection refused",
+			err:  &url.Error{Op: "Post", URL: "https://comms.example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+			want: false,
+		},
+		{
+			name: "sad case: unknown host",
+			err:  &url.Error{Op: "Post", URL: "https://comms.example.com", Err: &net.DNSError{Err: "no such host", Name: "comms.example.com"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mayHaveBeenSent(tt.err); got != tt.want {
+				t.Errorf("mayHaveBeenSent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
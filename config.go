@@ -0,0 +1,185 @@
+package silcomms
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/savannahghi/serverutils"
+)
+
+const (
+	// BaseURLEnvVarName is the environment variable holding the SIL-Comms base URL
+	BaseURLEnvVarName = "SIL_COMMS_BASE_URL"
+
+	// EmailEnvVarName is the environment variable holding the email used for authentication
+	EmailEnvVarName = "SIL_COMMS_EMAIL"
+
+	// PasswordEnvVarName is the environment variable holding the password used for authentication
+	PasswordEnvVarName = "SIL_COMMS_PASSWORD"
+
+	// SenderIDEnvVarName is the environment variable holding the default bulk SMS sender ID
+	SenderIDEnvVarName = "SIL_COMMS_SENDER_ID"
+)
+
+var (
+	// defaultHTTPTimeout is the timeout applied to each request made to the SIL comms API
+	defaultHTTPTimeout = 10 * time.Second
+
+	// defaultAccessTokenTimeout shows the access token expiry time.
+	// After the access token expires, one is required to obtain a new one
+	// It is used when the lifetime of the token cannot be determined
+	defaultAccessTokenTimeout = 59 * time.Minute
+
+	// defaultTokenRefreshFraction is the fraction of the token lifetime after which it is refreshed
+	defaultTokenRefreshFraction = 0.9
+
+	// minRefreshInterval prevents refreshing in a tight loop when a token is about to expire
+	minRefreshInterval = time.Second
+
+	// defaultLoginRetries is the number of login attempts made when the refresh token is rejected
+	defaultLoginRetries = 5
+
+	// defaultLoginBackoff is the wait before the first login retry. It doubles after every failed attempt
+	defaultLoginBackoff = time.Second
+
+	// defaultMaxLoginBackoff caps the wait between login retries
+	defaultMaxLoginBackoff = time.Minute
+)
+
+// Config holds the settings used to initialize a SIL Comms client
+type Config struct {
+	// BaseURL represents the SIL-Comms base URL
+	BaseURL string
+
+	// Email is used for authentication against the SIL comms API
+	Email string
+
+	// Password is used for authentication against the SIL comms API
+	Password string
+
+	// SenderID is used when sending bulk SMS without an explicit sender
+	SenderID string
+
+	// HTTPTimeout is the timeout applied to each request. Defaults to 10 seconds
+	HTTPTimeout time.Duration
+
+	// AccessTokenTimeout is how long an access token is used before it is refreshed
+	// when its lifetime is unknown. Defaults to 59 minutes
+	AccessTokenTimeout time.Duration
+
+	// TokenRefreshFraction is the fraction of the access token lifetime after which it is refreshed.
+	// The lifetime is read from the auth server expires in value or the JWT exp claim. Defaults to 0.9
+	TokenRefreshFraction float64
+
+	// LoginRetries is the number of login attempts made when the refresh token is rejected. Defaults to 5
+	LoginRetries int
+
+	// LoginBackoff is the wait before the first login retry. It doubles after every failed attempt. Defaults to 1 second
+	LoginBackoff time.Duration
+
+	// MaxLoginBackoff caps the wait between login retries. Defaults to 1 minute
+	MaxLoginBackoff time.Duration
+
+	// RetryPolicy decides whether requests that fail with transient errors are retried.
+	// Defaults to DefaultRetryPolicy which only retries idempotent requests
+	RetryPolicy RetryPolicy
+
+	// IdempotencyTTL is how long the response of a send request is returned
+	// for retries using the same idempotency key. Defaults to 1 hour
+	IdempotencyTTL time.Duration
+
+	// Region is used to normalize phone numbers written without a country code. Defaults to Kenya
+	Region *Region
+}
+
+// ConfigFromEnv loads the SIL Comms configuration from the SIL_COMMS_* environment variables
+func ConfigFromEnv() (*Config, error) {
+	baseURL, err := serverutils.GetEnvVar(BaseURLEnvVarName)
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := serverutils.GetEnvVar(EmailEnvVarName)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := serverutils.GetEnvVar(PasswordEnvVarName)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{
+		BaseURL:  baseURL,
+		Email:    email,
+		Password: password,
+		SenderID: os.Getenv(SenderIDEnvVarName),
+	}
+
+	return config, nil
+}
+
+// validate checks that the mandatory settings have been provided
+func (c Config) validate() error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("a SIL comms base URL must be provided")
+	}
+
+	if c.Email == "" || c.Password == "" {
+		return fmt.Errorf("SIL comms credentials must be provided")
+	}
+
+	if c.TokenRefreshFraction < 0 || c.TokenRefreshFraction > 1 {
+		return fmt.Errorf("token refresh fraction must be between 0 and 1, got: %v", c.TokenRefreshFraction)
+	}
+
+	if c.Region != nil {
+		if err := c.Region.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withDefaults returns a copy of the config with unset optional settings populated
+func (c Config) withDefaults() Config {
+	if c.HTTPTimeout <= 0 {
+		c.HTTPTimeout = defaultHTTPTimeout
+	}
+
+	if c.AccessTokenTimeout <= 0 {
+		c.AccessTokenTimeout = defaultAccessTokenTimeout
+	}
+
+	if c.TokenRefreshFraction <= 0 {
+		c.TokenRefreshFraction = defaultTokenRefreshFraction
+	}
+
+	if c.LoginRetries <= 0 {
+		c.LoginRetries = defaultLoginRetries
+	}
+
+	if c.LoginBackoff <= 0 {
+		c.LoginBackoff = defaultLoginBackoff
+	}
+
+	if c.MaxLoginBackoff <= 0 {
+		c.MaxLoginBackoff = defaultMaxLoginBackoff
+	}
+
+	if c.RetryPolicy == nil {
+		c.RetryPolicy = DefaultRetryPolicy()
+	}
+
+	if c.IdempotencyTTL <= 0 {
+		c.IdempotencyTTL = defaultIdempotencyTTL
+	}
+
+	if c.Region == nil {
+		c.Region = KenyaRegion()
+	}
+
+	return c
+}
@@ -0,0 +1,184 @@
+package silcomms
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{
+			name: "happy case: load config from env",
+			env: map[string]string{
+				BaseURLEnvVarName:  "https://comms.example.com",
+				EmailEnvVarName:    gofakeit.Email(),
+				PasswordEnvVarName: gofakeit.Password(true, true, true, true, false, 12),
+				SenderIDEnvVarName: "SIL",
+			},
+			wantErr: false,
+		},
+		{
+			name: "sad case: missing base url",
+			env: map[string]string{
+				BaseURLEnvVarName:  "",
+				EmailEnvVarName:    gofakeit.Email(),
+				PasswordEnvVarName: gofakeit.Password(true, true, true, true, false, 12),
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: missing email",
+			env: map[string]string{
+				BaseURLEnvVarName:  "https://comms.example.com",
+				EmailEnvVarName:    "",
+				PasswordEnvVarName: gofakeit.Password(true, true, true, true, false, 12),
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: missing password",
+			env: map[string]string{
+				BaseURLEnvVarName:  "https://comms.example.com",
+				EmailEnvVarName:    gofakeit.Email(),
+				PasswordEnvVarName: "",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			got, err := ConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConfigFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.SenderID != tt.env[SenderIDEnvVarName] {
+				t.Errorf("ConfigFromEnv() sender ID = %v, want %v", got.SenderID, tt.env[SenderIDEnvVarName])
+			}
+		})
+	}
+}
+
+func TestConfig_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{
+			name:    "happy case: valid config",
+			config:  testConfig,
+			wantErr: false,
+		},
+		{
+			name: "sad case: missing base url",
+			config: Config{
+				Email:    gofakeit.Email(),
+				Password: gofakeit.Password(true, true, true, true, false, 12),
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: missing credentials",
+			config: Config{
+				BaseURL: "https://comms.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: invalid token refresh fraction",
+			config: Config{
+				BaseURL:              "https://comms.example.com",
+				Email:                gofakeit.Email(),
+				Password:             gofakeit.Password(true, true, true, true, false, 12),
+				TokenRefreshFraction: 1.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: invalid region",
+			config: Config{
+				BaseURL:  "https://comms.example.com",
+				Email:    gofakeit.Email(),
+				Password: gofakeit.Password(true, true, true, true, false, 12),
+				Region:   &Region{CountryCode: "+254", NationalNumberLength: 9},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_withDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   Config
+	}{
+		{
+			name:   "happy case: populate defaults",
+			config: Config{},
+			want: Config{
+				HTTPTimeout:          defaultHTTPTimeout,
+				AccessTokenTimeout:   defaultAccessTokenTimeout,
+				TokenRefreshFraction: defaultTokenRefreshFraction,
+				LoginRetries:         defaultLoginRetries,
+				LoginBackoff:         defaultLoginBackoff,
+				MaxLoginBackoff:      defaultMaxLoginBackoff,
+				RetryPolicy:          DefaultRetryPolicy(),
+				IdempotencyTTL:       defaultIdempotencyTTL,
+				Region:               KenyaRegion(),
+			},
+		},
+		{
+			name: "happy case: keep provided values",
+			config: Config{
+				HTTPTimeout:          time.Second,
+				AccessTokenTimeout:   time.Minute,
+				TokenRefreshFraction: 0.5,
+				LoginRetries:         1,
+				LoginBackoff:         time.Millisecond,
+				MaxLoginBackoff:      time.Second,
+				RetryPolicy:          BackoffRetryPolicy{MaxAttempts: 1},
+				IdempotencyTTL:       time.Minute,
+				Region:               &Region{CountryCode: "255", NationalNumberLength: 9},
+			},
+			want: Config{
+				HTTPTimeout:          time.Second,
+				AccessTokenTimeout:   time.Minute,
+				TokenRefreshFraction: 0.5,
+				LoginRetries:         1,
+				LoginBackoff:         time.Millisecond,
+				MaxLoginBackoff:      time.Second,
+				RetryPolicy:          BackoffRetryPolicy{MaxAttempts: 1},
+				IdempotencyTTL:       time.Minute,
+				Region:               &Region{CountryCode: "255", NationalNumberLength: 9},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.withDefaults(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.withDefaults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package silcomms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// strictDecoding makes decoding fail when an API response has fields that the models do not know about.
+// It is set to 1 in tests so that the test fixtures are kept in line with the models
+var strictDecoding int32
+
+// timeLayouts are the ISO-8601 variants of the timestamps returned by the API
+// The API returns timestamps such as 2022-08-04 14:11:17.206377+03:00 with a space instead of a T
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// decodeJSON decodes the JSON read from r into v
+func decodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+
+	if atomic.LoadInt32(&strictDecoding) == 1 {
+		decoder.DisallowUnknownFields()
+	}
+
+	return decoder.Decode(v)
+}
+
+// unmarshalJSON decodes JSON data into v
+// Models with custom UnmarshalJSON methods use it so that strict decoding applies to their fields
+func unmarshalJSON(data []byte, v interface{}) error {
+	return decodeJSON(bytes.NewReader(data), v)
+}
+
+// decodeResponse decodes an API response whose data is decoded into the model T
+func decodeResponse[T any](r io.Reader) (*APIResponse[T], error) {
+	var resp APIResponse[T]
+
+	if err := decodeJSON(r, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// timestamp is an ISO-8601 timestamp in any of the layouts returned by the API
+// Models use it to shadow their time.Time fields when decoding
+type timestamp struct {
+	time.Time
+}
+
+// UnmarshalJSON parses a timestamp. A null or empty timestamp is left as the zero time
+func (t *timestamp) UnmarshalJSON(data []byte) error {
+	var value *string
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid timestamp %s: %w", data, err)
+	}
+
+	if value == nil || *value == "" {
+		t.Time = time.Time{}
+
+		return nil
+	}
+
+	parsed, err := parseTime(*value)
+	if err != nil {
+		return err
+	}
+
+	t.Time = parsed
+
+	return nil
+}
+
+// ptr returns the time of an optional timestamp, nil when it is missing or empty
+func (t *timestamp) ptr() *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+
+	value := t.Time
+
+	return &value
+}
+
+// parseTime parses an ISO-8601 timestamp in any of the layouts returned by the API
+// Timestamps without a time zone are assumed to be in UTC
+func parseTime(value string) (time.Time, error) {
+	normalized := strings.Replace(strings.TrimSpace(value), " ", "T", 1)
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
@@ -0,0 +1,266 @@
+package silcomms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func TestParseTime(t *testing.T) {
+	eat := time.FixedZone("EAT", 3*60*60)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "api timestamp",
+			value: "2022-08-04 14:11:17.206377+03:00",
+			want:  time.Date(2022, 8, 4, 14, 11, 17, 206377000, eat),
+		},
+		{
+			name:  "rfc3339 timestamp",
+			value: "2022-08-04T11:11:17Z",
+			want:  time.Date(2022, 8, 4, 11, 11, 17, 0, time.UTC),
+		},
+		{
+			name:  "offset without colon",
+			value: "2022-08-04T14:11:17+0300",
+			want:  time.Date(2022, 8, 4, 14, 11, 17, 0, eat),
+		},
+		{
+			name:  "offset in hours",
+			value: "2022-08-04 14:11:17.5+03",
+			want:  time.Date(2022, 8, 4, 14, 11, 17, 500000000, eat),
+		},
+		{
+			name:  "timestamp without time zone",
+			value: "2022-08-04T11:11:17.206377",
+			want:  time.Date(2022, 8, 4, 11, 11, 17, 206377000, time.UTC),
+		},
+		{
+			name:  "date",
+			value: "2022-08-04",
+			want:  time.Date(2022, 8, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid timestamp",
+			value:   "04/08/2022",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscription_UnmarshalJSON(t *testing.T) {
+	activated := time.Date(2022, 8, 4, 11, 11, 17, 206377000, time.UTC)
+
+	tests := []struct {
+		name            string
+		data            string
+		wantDeactivated bool
+		wantErr         bool
+	}{
+		{
+			name: "active subscription",
+			data: `{"activation_date": "2022-08-04 14:11:17.206377+03:00", "deactivation_date": null}`,
+		},
+		{
+			name: "empty deactivation date",
+			data: `{"activation_date": "2022-08-04 14:11:17.206377+03:00", "deactivation_date": ""}`,
+		},
+		{
+			name:            "deactivated subscription",
+			data:            `{"activation_date": "2022-08-04 14:11:17.206377+03:00", "deactivation_date": "2022-08-05 14:11:17.206377+03:00"}`,
+			wantDeactivated: true,
+		},
+		{
+			name:    "invalid timestamp",
+			data:    `{"activation_date": "yesterday"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid timestamp type",
+			data:    `{"activation_date": 1659611477}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Subscription
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Subscription.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !got.ActivationDate.Equal(activated) {
+				t.Errorf("Subscription.UnmarshalJSON() activation date = %v, want %v", got.ActivationDate, activated)
+			}
+
+			if (got.DeactivationDate != nil) != tt.wantDeactivated {
+				t.Errorf("Subscription.UnmarshalJSON() deactivation date = %v, want deactivated %v", got.DeactivationDate, tt.wantDeactivated)
+			}
+		})
+	}
+}
+
+func TestDecodeResponse_strict(t *testing.T) {
+	data := `{"status": "success", "message": "success", "data": {"guid": "e602b8b8", "offer": "01262626626", "shortcode": "20475"}}`
+
+	tests := []struct {
+		name    string
+		strict  bool
+		wantErr bool
+	}{
+		{
+			name:    "unknown fields are ignored by default",
+			strict:  false,
+			wantErr: false,
+		},
+		{
+			name:    "unknown fields are rejected in strict mode",
+			strict:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStrictDecoding(t, tt.strict)
+
+			got, err := decodeResponse[Subscription](strings.NewReader(data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeResponse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.Data.GUID != "e602b8b8" {
+				t.Errorf("decodeResponse() = %+v, want the subscription", got.Data)
+			}
+		})
+	}
+}
+
+// subscriptionsPage returns a page of subscriptions as returned by the API
+func subscriptionsPage(b *testing.B, size int) []byte {
+	results := make([]map[string]interface{}, 0, size)
+
+	for i := 0; i < size; i++ {
+		results = append(results, map[string]interface{}{
+			"guid":              fmt.Sprintf("e602b8b8-9591-4526-915d-%012d", i),
+			"gateway":           "SAFARICOM",
+			"offer":             "01262626626",
+			"msisdn":            fmt.Sprintf("+2547%08d", i),
+			"link_id":           fmt.Sprintf("%015d", i),
+			"activation_date":   "2022-08-04 14:11:17.206377+03:00",
+			"deactivation_date": nil,
+			"deactivation_type": "",
+			"sms":               []interface{}{},
+			"created":           "2022-08-04 14:11:17.206377+03:00",
+			"updated":           "2022-08-04 14:11:17.206377+03:00",
+		})
+	}
+
+	data, err := json.Marshal(APIResponse[any]{
+		Status:  StatusSuccess,
+		Message: "success",
+		Data: map[string]interface{}{
+			"count":    size,
+			"next":     nil,
+			"previous": nil,
+			"results":  results,
+		},
+	})
+	if err != nil {
+		b.Fatalf("failed to encode subscriptions page: %v", err)
+	}
+
+	return data
+}
+
+// legacyDecodeSubscriptions decodes a page of subscriptions the way it was done before the generic API response
+// The API response is decoded into maps which are then decoded into the models using mapstructure
+func legacyDecodeSubscriptions(data []byte) ([]*Subscription, error) {
+	var resp APIResponse[interface{}]
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	decode := func(input, output interface{}) error {
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			TagName:    "json",
+			DecodeHook: mapstructure.StringToTimeHookFunc("2006-01-02 15:04:05.999999-07:00"),
+			Result:     output,
+		})
+		if err != nil {
+			return err
+		}
+
+		return decoder.Decode(input)
+	}
+
+	var results ResultsResponse
+
+	if err := decode(resp.Data, &results); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []*Subscription
+
+	if err := decode(results.Results, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func BenchmarkDecodeSubscriptionsPage(b *testing.B) {
+	data := subscriptionsPage(b, maxPageSize)
+
+	b.Run("legacy mapstructure", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			subscriptions, err := legacyDecodeSubscriptions(data)
+			if err != nil || len(subscriptions) != maxPageSize {
+				b.Fatalf("failed to decode subscriptions page: %v", err)
+			}
+		}
+	})
+
+	b.Run("generic api response", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			resp, err := decodeResponse[List[*Subscription]](bytes.NewReader(data))
+			if err != nil || len(resp.Data.Results) != maxPageSize {
+				b.Fatalf("failed to decode subscriptions page: %v", err)
+			}
+		}
+	})
+}
@@ -1,5 +1,13 @@
 package silcomms
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
 // Status is the API response status
 type Status string
 
@@ -26,3 +34,241 @@ func (s Status) IsValid() bool {
 func (s Status) String() string {
 	return string(s)
 }
+
+// MessageState is the delivery state of an SMS or the processing state of a bulk SMS
+type MessageState string
+
+const (
+	// MessageStatePending is an SMS that has been accepted but not yet queued for sending
+	MessageStatePending MessageState = "PENDING"
+	// MessageStateQueued is an SMS waiting to be sent to the gateway
+	MessageStateQueued MessageState = "QUEUED"
+	// MessageStateProcessing is a bulk SMS whose recipients are being sent the SMS
+	MessageStateProcessing MessageState = "PROCESSING"
+	// MessageStateSent is an SMS that has been handed to the gateway
+	MessageStateSent MessageState = "SENT"
+	// MessageStateDelivered is an SMS that the gateway has delivered to the recipient
+	MessageStateDelivered MessageState = "DELIVERED"
+	// MessageStateReceived is an inbound SMS that has been received from a subscriber
+	MessageStateReceived MessageState = "RECEIVED"
+	// MessageStateCompleted is a bulk SMS that has been sent to all its recipients
+	MessageStateCompleted MessageState = "COMPLETED"
+	// MessageStateFailed is an SMS that could not be sent
+	MessageStateFailed MessageState = "FAILED"
+	// MessageStateRejected is an SMS that the gateway refused to send
+	MessageStateRejected MessageState = "REJECTED"
+	// MessageStateExpired is an SMS that was not delivered before its validity period ended
+	MessageStateExpired MessageState = "EXPIRED"
+)
+
+// IsValid returns true if a message state is valid
+func (e MessageState) IsValid() bool {
+	switch e {
+	case MessageStatePending, MessageStateQueued, MessageStateProcessing, MessageStateSent, MessageStateDelivered,
+		MessageStateReceived, MessageStateCompleted, MessageStateFailed, MessageStateRejected, MessageStateExpired:
+		return true
+	}
+
+	return false
+}
+
+// IsTerminal returns true if the message state will not change anymore
+func (e MessageState) IsTerminal() bool {
+	switch e {
+	case MessageStateDelivered, MessageStateReceived, MessageStateCompleted,
+		MessageStateFailed, MessageStateRejected, MessageStateExpired:
+		return true
+	}
+
+	return false
+}
+
+// IsDelivered returns true if the SMS reached its recipient
+func (e MessageState) IsDelivered() bool {
+	return e == MessageStateDelivered || e == MessageStateReceived
+}
+
+// IsFailed returns true if the SMS will never reach its recipient
+func (e MessageState) IsFailed() bool {
+	switch e {
+	case MessageStateFailed, MessageStateRejected, MessageStateExpired:
+		return true
+	}
+
+	return false
+}
+
+// String representation of message state
+func (e MessageState) String() string {
+	return string(e)
+}
+
+// UnmarshalJSON reads a message state ignoring its case
+// Unknown states are kept so that new states added by the API can be decoded, IsValid reports them
+func (e *MessageState) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, (*string)(e))
+}
+
+// UnmarshalGQL converts the input, if valid, into a message state value
+func (e *MessageState) UnmarshalGQL(v interface{}) error {
+	return unmarshalEnumGQL(v, (*string)(e), "MessageState", func() bool { return e.IsValid() })
+}
+
+// MarshalGQL writes the message state as a quoted string
+func (e MessageState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// SMSType is the kind of SMS that was sent
+type SMSType string
+
+const (
+	// SMSTypeBulk is an SMS sent to one or more recipients using a sender ID
+	SMSTypeBulk SMSType = "BULK"
+	// SMSTypePremium is an SMS sent to the subscribers of a premium offer
+	SMSTypePremium SMSType = "PREMIUM"
+)
+
+// IsValid returns true if an SMS type is valid
+func (e SMSType) IsValid() bool {
+	switch e {
+	case SMSTypeBulk, SMSTypePremium:
+		return true
+	}
+
+	return false
+}
+
+// String representation of SMS type
+func (e SMSType) String() string {
+	return string(e)
+}
+
+// UnmarshalJSON reads an SMS type ignoring its case
+// Unknown types are kept so that new types added by the API can be decoded, IsValid reports them
+func (e *SMSType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, (*string)(e))
+}
+
+// UnmarshalGQL converts the input, if valid, into an SMS type value
+func (e *SMSType) UnmarshalGQL(v interface{}) error {
+	return unmarshalEnumGQL(v, (*string)(e), "SMSType", func() bool { return e.IsValid() })
+}
+
+// MarshalGQL writes the SMS type as a quoted string
+func (e SMSType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Direction tells apart the SMS we send from the SMS sent to us by subscribers
+type Direction string
+
+const (
+	// DirectionInbound is an SMS sent by a subscriber to one of our short codes
+	DirectionInbound Direction = "INBOUND"
+	// DirectionOutbound is an SMS sent by us
+	DirectionOutbound Direction = "OUTBOUND"
+)
+
+// IsValid returns true if a direction is valid
+func (e Direction) IsValid() bool {
+	switch e {
+	case DirectionInbound, DirectionOutbound:
+		return true
+	}
+
+	return false
+}
+
+// String representation of direction
+func (e Direction) String() string {
+	return string(e)
+}
+
+// UnmarshalJSON reads a direction ignoring its case
+// Unknown directions are kept so that new directions added by the API can be decoded, IsValid reports them
+func (e *Direction) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, (*string)(e))
+}
+
+// UnmarshalGQL converts the input, if valid, into a direction value
+func (e *Direction) UnmarshalGQL(v interface{}) error {
+	return unmarshalEnumGQL(v, (*string)(e), "Direction", func() bool { return e.IsValid() })
+}
+
+// MarshalGQL writes the direction as a quoted string
+func (e Direction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// DeactivationType is the reason a subscription was deactivated
+type DeactivationType string
+
+const (
+	// DeactivationTypeUserInitiated is a subscription deactivated at the subscriber's request e.g when a patient opts out
+	DeactivationTypeUserInitiated DeactivationType = "USER_INITIATED"
+	// DeactivationTypeSystemInitiated is a subscription deactivated by the app e.g when a program ends
+	DeactivationTypeSystemInitiated DeactivationType = "SYSTEM_INITIATED"
+	// DeactivationTypeCarrierInitiated is a subscription deactivated by the carrier e.g when a line is disconnected
+	DeactivationTypeCarrierInitiated DeactivationType = "CARRIER_INITIATED"
+)
+
+// IsValid returns true if a deactivation type is valid
+func (e DeactivationType) IsValid() bool {
+	switch e {
+	case DeactivationTypeUserInitiated, DeactivationTypeSystemInitiated, DeactivationTypeCarrierInitiated:
+		return true
+	}
+
+	return false
+}
+
+// String representation of deactivation type
+func (e DeactivationType) String() string {
+	return string(e)
+}
+
+// UnmarshalJSON reads a deactivation type ignoring its case
+// Unknown types are kept so that new types added by the API can be decoded, IsValid reports them
+func (e *DeactivationType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, (*string)(e))
+}
+
+// UnmarshalGQL converts the input, if valid, into a deactivation type value
+func (e *DeactivationType) UnmarshalGQL(v interface{}) error {
+	return unmarshalEnumGQL(v, (*string)(e), "DeactivationType", func() bool { return e.IsValid() })
+}
+
+// MarshalGQL writes the deactivation type as a quoted string
+func (e DeactivationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// unmarshalEnumJSON reads a JSON string into an enum value converting it to upper case
+func unmarshalEnumJSON(data []byte, value *string) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*value = strings.ToUpper(s)
+
+	return nil
+}
+
+// unmarshalEnumGQL reads a GraphQL input into an enum value and checks that it is valid
+func unmarshalEnumGQL(v interface{}, value *string, name string, isValid func() bool) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*value = s
+
+	if !isValid() {
+		return fmt.Errorf("%s is not a valid %s", s, name)
+	}
+
+	return nil
+}
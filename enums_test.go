@@ -1,6 +1,11 @@
 package silcomms
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
 
 func TestStatus_String(t *testing.T) {
 	tests := []struct {
@@ -48,3 +53,133 @@ func TestStatus_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestEnums_IsValid(t *testing.T) {
+	tests := []struct {
+		name string
+		e    interface{ IsValid() bool }
+		want bool
+	}{
+		{name: "valid message state", e: MessageStateDelivered, want: true},
+		{name: "invalid message state", e: MessageState("LOST"), want: false},
+		{name: "valid sms type", e: SMSTypePremium, want: true},
+		{name: "invalid sms type", e: SMSType("MMS"), want: false},
+		{name: "valid direction", e: DirectionInbound, want: true},
+		{name: "invalid direction", e: Direction("SIDEWAYS"), want: false},
+		{name: "valid deactivation type", e: DeactivationTypeUserInitiated, want: true},
+		{name: "invalid deactivation type", e: DeactivationType("UNKNOWN"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageState_helpers(t *testing.T) {
+	tests := []struct {
+		name          string
+		e             MessageState
+		wantTerminal  bool
+		wantDelivered bool
+		wantFailed    bool
+	}{
+		{name: "queued", e: MessageStateQueued},
+		{name: "sent", e: MessageStateSent},
+		{name: "delivered", e: MessageStateDelivered, wantTerminal: true, wantDelivered: true},
+		{name: "received", e: MessageStateReceived, wantTerminal: true, wantDelivered: true},
+		{name: "completed", e: MessageStateCompleted, wantTerminal: true},
+		{name: "failed", e: MessageStateFailed, wantTerminal: true, wantFailed: true},
+		{name: "expired", e: MessageStateExpired, wantTerminal: true, wantFailed: true},
+		{name: "unknown", e: MessageState("LOST")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.IsTerminal(); got != tt.wantTerminal {
+				t.Errorf("MessageState.IsTerminal() = %v, want %v", got, tt.wantTerminal)
+			}
+
+			if got := tt.e.IsDelivered(); got != tt.wantDelivered {
+				t.Errorf("MessageState.IsDelivered() = %v, want %v", got, tt.wantDelivered)
+			}
+
+			if got := tt.e.IsFailed(); got != tt.wantFailed {
+				t.Errorf("MessageState.IsFailed() = %v, want %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestMessageState_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    MessageState
+		wantErr bool
+	}{
+		{name: "upper case", data: `"DELIVERED"`, want: MessageStateDelivered},
+		{name: "lower case", data: `"delivered"`, want: MessageStateDelivered},
+		{name: "unknown state is kept", data: `"LOST"`, want: MessageState("LOST")},
+		{name: "not a string", data: `1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got MessageState
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MessageState.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("MessageState.UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// gqlEnum is an enum that can be used as a GraphQL scalar
+type gqlEnum interface {
+	UnmarshalGQL(v interface{}) error
+	MarshalGQL(w io.Writer)
+}
+
+func TestEnums_GQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		e        gqlEnum
+		input    interface{}
+		wantJSON string
+		wantErr  bool
+	}{
+		{name: "valid message state", e: new(MessageState), input: "SENT", wantJSON: `"SENT"`},
+		{name: "invalid message state", e: new(MessageState), input: "LOST", wantErr: true},
+		{name: "valid sms type", e: new(SMSType), input: "BULK", wantJSON: `"BULK"`},
+		{name: "valid direction", e: new(Direction), input: "OUTBOUND", wantJSON: `"OUTBOUND"`},
+		{name: "invalid direction", e: new(Direction), input: 1, wantErr: true},
+		{name: "valid deactivation type", e: new(DeactivationType), input: "SYSTEM_INITIATED", wantJSON: `"SYSTEM_INITIATED"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.e.UnmarshalGQL(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalGQL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			w := &bytes.Buffer{}
+			tt.e.MarshalGQL(w)
+
+			if got := w.String(); got != tt.wantJSON {
+				t.Errorf("MarshalGQL() = %v, want %v", got, tt.wantJSON)
+			}
+		})
+	}
+}
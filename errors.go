@@ -0,0 +1,173 @@
+package silcomms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize limits how much of an error response body is read
+const maxErrorBodySize = 1 << 20
+
+var (
+	// ErrUnauthorized is matched by API errors caused by missing or invalid credentials
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrRateLimited is matched by API errors caused by sending too many requests
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrValidation is matched by API errors caused by an invalid request payload
+	ErrValidation = errors.New("validation failed")
+
+	// ErrNotFound is matched by API errors caused by a missing resource
+	ErrNotFound = errors.New("not found")
+
+	// ErrSendOutcomeUnknown is matched by send errors where it is unknown whether the SMS was sent
+	ErrSendOutcomeUnknown = errors.New("send outcome unknown")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is reused for a different send request
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+)
+
+// SendOutcomeUnknownError is returned when a send request fails after it was sent, e.g on a timeout,
+// so the SMS may or may not have been sent. Retry with the same idempotency key to send the request again
+// with the same Idempotency-Key header, which lets the API deduplicate it instead of sending a second SMS.
+// It can be matched against ErrSendOutcomeUnknown using errors.Is
+type SendOutcomeUnknownError struct {
+	// IdempotencyKey is the key of the send request whose outcome is unknown
+	IdempotencyKey string
+
+	// Err is the underlying failure
+	Err error
+}
+
+// Error returns the idempotency key and the underlying failure
+func (e *SendOutcomeUnknownError) Error() string {
+	return fmt.Sprintf("outcome of send request with idempotency key %s is unknown: %s", e.IdempotencyKey, e.Err)
+}
+
+// Unwrap returns the underlying failure
+func (e *SendOutcomeUnknownError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrSendOutcomeUnknown
+func (e *SendOutcomeUnknownError) Is(target error) bool {
+	return target == ErrSendOutcomeUnknown
+}
+
+// APIError is returned when the SIL comms API responds with an unexpected status code
+// It can be matched against the sentinel errors using errors.Is
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+
+	// Method and Path identify the request that failed
+	Method string
+	Path   string
+
+	// RequestID is the value of the X-Request-ID response header when present
+	RequestID string
+
+	// Response is the decoded status, message and data envelope of the error response
+	Response *APIErrorResponse
+
+	// Detail is the decoded detail, code and message list of the error response
+	Detail *ErrorResponse
+}
+
+// newAPIError builds an APIError from an unexpected API response
+// The body is decoded on a best effort basis since error responses are not always JSON
+func newAPIError(response *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		RequestID:  response.Header.Get("X-Request-ID"),
+	}
+
+	if response.Request != nil {
+		apiErr.Method = response.Request.Method
+		apiErr.Path = response.Request.URL.Path
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+
+	var envelope APIErrorResponse
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		apiErr.Response = &envelope
+	}
+
+	var detail ErrorResponse
+	if err := json.Unmarshal(body, &detail); err == nil {
+		apiErr.Detail = &detail
+	}
+
+	return apiErr
+}
+
+// Error returns the status code and the most specific error detail available
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("got: %d", e.StatusCode)
+
+	if detail := e.detail(); detail != "" {
+		msg = fmt.Sprintf("%s, error detail: %s", msg, detail)
+	}
+
+	if e.RequestID != "" {
+		msg = fmt.Sprintf("%s, request id: %s", msg, e.RequestID)
+	}
+
+	return msg
+}
+
+// detail returns a human readable description of the error response
+func (e *APIError) detail() string {
+	if e.Detail != nil {
+		if e.Detail.Detail != "" {
+			return e.Detail.Detail
+		}
+
+		messages := []string{}
+
+		for _, message := range e.Detail.Message {
+			if message.Message != "" {
+				messages = append(messages, message.Message)
+			}
+		}
+
+		if len(messages) > 0 {
+			return strings.Join(messages, "; ")
+		}
+	}
+
+	if e.Response != nil {
+		if len(e.Response.Data) > 0 {
+			return fmt.Sprintf("%s", e.Response.Data)
+		}
+
+		return e.Response.Message
+	}
+
+	return ""
+}
+
+// Is reports whether the API error matches one of the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+package silcomms
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func Test_newAPIError(t *testing.T) {
+	request := &http.Request{
+		Method: http.MethodPost,
+		URL:    &url.URL{Path: "/v1/sms/bulk/"},
+	}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		requestID  string
+		wantErr    string
+	}{
+		{
+			name:       "happy case: detail error response",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"detail": "Given token not valid for any token type", "code": "token_not_valid"}`,
+			requestID:  "abc123",
+			wantErr:    "got: 401, error detail: Given token not valid for any token type, request id: abc123",
+		},
+		{
+			name:       "happy case: message list error response",
+			statusCode: http.StatusBadRequest,
+			body:       `{"message": [{"message": "invalid recipient"}, {"message": "invalid sender"}]}`,
+			wantErr:    "got: 400, error detail: invalid recipient; invalid sender",
+		},
+		{
+			name:       "happy case: envelope error response",
+			statusCode: http.StatusNotFound,
+			body:       `{"status": "error", "message": "subscription not found"}`,
+			wantErr:    "got: 404, error detail: subscription not found",
+		},
+		{
+			name:       "happy case: non JSON error response",
+			statusCode: http.StatusBadGateway,
+			body:       "<html>Bad Gateway</html>",
+			wantErr:    "got: 502",
+		},
+		{
+			name:       "happy case: empty error response",
+			statusCode: http.StatusTooManyRequests,
+			wantErr:    "got: 429",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &http.Response{
+				StatusCode: tt.statusCode,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+				Request:    request,
+			}
+			response.Header.Set("X-Request-ID", tt.requestID)
+
+			got := newAPIError(response)
+
+			if got.Error() != tt.wantErr {
+				t.Errorf("newAPIError() = %v, want %v", got.Error(), tt.wantErr)
+			}
+
+			if got.Method != http.MethodPost || got.Path != "/v1/sms/bulk/" {
+				t.Errorf("newAPIError() request = %s %s, want %s %s", got.Method, got.Path, http.MethodPost, "/v1/sms/bulk/")
+			}
+		})
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		target     error
+		want       bool
+	}{
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			target:     ErrUnauthorized,
+			want:       true,
+		},
+		{
+			name:       "forbidden",
+			statusCode: http.StatusForbidden,
+			target:     ErrUnauthorized,
+			want:       true,
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			target:     ErrRateLimited,
+			want:       true,
+		},
+		{
+			name:       "bad request",
+			statusCode: http.StatusBadRequest,
+			target:     ErrValidation,
+			want:       true,
+		},
+		{
+			name:       "unprocessable entity",
+			statusCode: http.StatusUnprocessableEntity,
+			target:     ErrValidation,
+			want:       true,
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			target:     ErrNotFound,
+			want:       true,
+		},
+		{
+			name:       "mismatched sentinel",
+			statusCode: http.StatusNotFound,
+			target:     ErrUnauthorized,
+			want:       false,
+		},
+		{
+			name:       "unrelated error",
+			statusCode: http.StatusInternalServerError,
+			target:     ErrClientClosed,
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: tt.statusCode})
+
+			if got := errors.Is(err, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
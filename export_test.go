@@ -0,0 +1,30 @@
+package silcomms
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+// TestMain decodes API responses strictly so that the test fixtures are kept in line with the models
+func TestMain(m *testing.M) {
+	atomic.StoreInt32(&strictDecoding, 1)
+
+	os.Exit(m.Run())
+}
+
+// SetStrictDecoding sets whether decoding API responses fails on unknown fields until the test ends
+func SetStrictDecoding(t testing.TB, strict bool) {
+	previous := atomic.LoadInt32(&strictDecoding)
+
+	value := int32(0)
+	if strict {
+		value = 1
+	}
+
+	atomic.StoreInt32(&strictDecoding, value)
+
+	t.Cleanup(func() {
+		atomic.StoreInt32(&strictDecoding, previous)
+	})
+}
@@ -0,0 +1,256 @@
+package silcomms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPageSize is the largest page size accepted by the SIL comms API
+const maxPageSize = 1000
+
+// subscriptionOrderingFields are the fields that subscriptions can be ordered by
+var subscriptionOrderingFields = []string{
+	"activation_date",
+	"deactivation_date",
+	"created",
+	"updated",
+	"msisdn",
+	"offer",
+}
+
+// smsOrderingFields are the fields that SMS can be ordered by
+var smsOrderingFields = []string{
+	"created",
+	"updated",
+	"msisdn",
+	"sender",
+	"state",
+}
+
+// SubscriptionFilter narrows down the subscriptions returned when listing subscriptions
+// Unset fields are not used for filtering
+type SubscriptionFilter struct {
+	Offer   string
+	Msisdn  string
+	Gateway string
+
+	// Active lists only active subscriptions when true and only deactivated subscriptions when false
+	Active *bool
+
+	// ActivatedAfter and ActivatedBefore limit the activation date range, both ends are inclusive
+	ActivatedAfter  *time.Time
+	ActivatedBefore *time.Time
+
+	// Ordering is the field used to sort the subscriptions. Prefix it with - to sort in descending order
+	Ordering string
+
+	// PageSize is the number of subscriptions in each page. The API default is used when unset
+	PageSize int
+}
+
+// Validate checks that the filter only uses supported values
+func (f SubscriptionFilter) Validate() error {
+	if err := validateOrdering(f.Ordering, subscriptionOrderingFields); err != nil {
+		return err
+	}
+
+	if err := validateDateRange(f.ActivatedAfter, f.ActivatedBefore); err != nil {
+		return err
+	}
+
+	return validatePageSize(f.PageSize)
+}
+
+// normalize validates the filter and normalizes its msisdn into E.164 format using the provided region
+func (f SubscriptionFilter) normalize(region Region) (SubscriptionFilter, error) {
+	if err := f.Validate(); err != nil {
+		return f, err
+	}
+
+	msisdn, err := normalizeFilterMSISDN(f.Msisdn, region)
+	if err != nil {
+		return f, err
+	}
+
+	f.Msisdn = msisdn
+
+	return f, nil
+}
+
+// QueryParams encodes the filter into the query params understood by the SIL comms API
+func (f SubscriptionFilter) QueryParams() map[string]string {
+	params := map[string]string{}
+
+	setParam(params, "offer", f.Offer)
+	setParam(params, "msisdn", f.Msisdn)
+	setParam(params, "gateway", f.Gateway)
+	setParam(params, "ordering", f.Ordering)
+
+	if f.Active != nil {
+		params["active"] = strconv.FormatBool(*f.Active)
+	}
+
+	if f.ActivatedAfter != nil {
+		params["activation_date__gte"] = f.ActivatedAfter.Format(time.RFC3339)
+	}
+
+	if f.ActivatedBefore != nil {
+		params["activation_date__lte"] = f.ActivatedBefore.Format(time.RFC3339)
+	}
+
+	if f.PageSize > 0 {
+		params["page_size"] = strconv.Itoa(f.PageSize)
+	}
+
+	return params
+}
+
+// SMSFilter narrows down the SMS returned when listing SMS
+// Unset fields are not used for filtering
+type SMSFilter struct {
+	Msisdn    string
+	Sender    string
+	State     MessageState
+	Direction Direction
+	SMSType   SMSType
+
+	// Bulk lists only the SMS sent as part of the bulk SMS with this GUID
+	Bulk string
+
+	// CreatedAfter and CreatedBefore limit the creation date range, both ends are inclusive
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	// Ordering is the field used to sort the SMS. Prefix it with - to sort in descending order
+	Ordering string
+
+	// PageSize is the number of SMS in each page. The API default is used when unset
+	PageSize int
+}
+
+// Validate checks that the filter only uses supported values
+func (f SMSFilter) Validate() error {
+	if f.State != "" && !f.State.IsValid() {
+		return fmt.Errorf("invalid sms state %q", f.State)
+	}
+
+	if f.Direction != "" && !f.Direction.IsValid() {
+		return fmt.Errorf("invalid sms direction %q", f.Direction)
+	}
+
+	if f.SMSType != "" && !f.SMSType.IsValid() {
+		return fmt.Errorf("invalid sms type %q", f.SMSType)
+	}
+
+	if err := validateOrdering(f.Ordering, smsOrderingFields); err != nil {
+		return err
+	}
+
+	if err := validateDateRange(f.CreatedAfter, f.CreatedBefore); err != nil {
+		return err
+	}
+
+	return validatePageSize(f.PageSize)
+}
+
+// normalize validates the filter and normalizes its msisdn into E.164 format using the provided region
+func (f SMSFilter) normalize(region Region) (SMSFilter, error) {
+	if err := f.Validate(); err != nil {
+		return f, err
+	}
+
+	msisdn, err := normalizeFilterMSISDN(f.Msisdn, region)
+	if err != nil {
+		return f, err
+	}
+
+	f.Msisdn = msisdn
+
+	return f, nil
+}
+
+// QueryParams encodes the filter into the query params understood by the SIL comms API
+func (f SMSFilter) QueryParams() map[string]string {
+	params := map[string]string{}
+
+	setParam(params, "msisdn", f.Msisdn)
+	setParam(params, "sender", f.Sender)
+	setParam(params, "state", f.State.String())
+	setParam(params, "direction", f.Direction.String())
+	setParam(params, "sms_type", f.SMSType.String())
+	setParam(params, "bulk", f.Bulk)
+	setParam(params, "ordering", f.Ordering)
+
+	if f.CreatedAfter != nil {
+		params["created__gte"] = f.CreatedAfter.Format(time.RFC3339)
+	}
+
+	if f.CreatedBefore != nil {
+		params["created__lte"] = f.CreatedBefore.Format(time.RFC3339)
+	}
+
+	if f.PageSize > 0 {
+		params["page_size"] = strconv.Itoa(f.PageSize)
+	}
+
+	return params
+}
+
+// normalizeFilterMSISDN normalizes a filter msisdn into E.164 format. An unset msisdn is left unset
+func normalizeFilterMSISDN(raw string, region Region) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	msisdn, err := ParseMSISDN(raw, region)
+	if err != nil {
+		return "", err
+	}
+
+	return msisdn.String(), nil
+}
+
+// setParam adds a query param when the value is set
+func setParam(params map[string]string, key, value string) {
+	if value != "" {
+		params[key] = value
+	}
+}
+
+// validateOrdering checks that the ordering is one of the allowed fields with an optional - prefix
+func validateOrdering(ordering string, fields []string) error {
+	if ordering == "" {
+		return nil
+	}
+
+	field := strings.TrimPrefix(ordering, "-")
+
+	for _, allowed := range fields {
+		if field == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid ordering %q, must be one of: %s", ordering, strings.Join(fields, ", "))
+}
+
+// validateDateRange checks that the start of a date range is not after its end
+func validateDateRange(from, to *time.Time) error {
+	if from != nil && to != nil && from.After(*to) {
+		return fmt.Errorf("invalid date range, %s is after %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// validatePageSize checks that the page size is within the range accepted by the API
+// A page size of 0 leaves it unset so that the API's default is used
+func validatePageSize(pageSize int) error {
+	if pageSize < 0 || pageSize > maxPageSize {
+		return fmt.Errorf("invalid page size %d, must be at most %d or 0 for the API default", pageSize, maxPageSize)
+	}
+
+	return nil
+}
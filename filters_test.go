@@ -0,0 +1,331 @@
+package silcomms
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionFilter_Validate(t *testing.T) {
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  SubscriptionFilter
+		wantErr bool
+	}{
+		{
+			name:    "happy case: empty filter",
+			filter:  SubscriptionFilter{},
+			wantErr: false,
+		},
+		{
+			name: "happy case: complete filter",
+			filter: SubscriptionFilter{
+				Offer:           "01262626626",
+				Msisdn:          "+254722345678",
+				Gateway:         "SAFARICOM",
+				ActivatedAfter:  &yesterday,
+				ActivatedBefore: &now,
+				Ordering:        "-activation_date",
+				PageSize:        100,
+			},
+			wantErr: false,
+		},
+		{
+			name: "sad case: unknown ordering field",
+			filter: SubscriptionFilter{
+				Ordering: "activated",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: inverted activation date range",
+			filter: SubscriptionFilter{
+				ActivatedAfter:  &now,
+				ActivatedBefore: &yesterday,
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: page size too large",
+			filter: SubscriptionFilter{
+				PageSize: maxPageSize + 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: negative page size",
+			filter: SubscriptionFilter{
+				PageSize: -1,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("SubscriptionFilter.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscriptionFilter_QueryParams(t *testing.T) {
+	active := false
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 8, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter SubscriptionFilter
+		want   map[string]string
+	}{
+		{
+			name:   "empty filter",
+			filter: SubscriptionFilter{},
+			want:   map[string]string{},
+		},
+		{
+			name: "complete filter",
+			filter: SubscriptionFilter{
+				Offer:           "01262626626",
+				Msisdn:          "+254722345678",
+				Gateway:         "SAFARICOM",
+				Active:          &active,
+				ActivatedAfter:  &from,
+				ActivatedBefore: &to,
+				Ordering:        "-activation_date",
+				PageSize:        100,
+			},
+			want: map[string]string{
+				"offer":                "01262626626",
+				"msisdn":               "+254722345678",
+				"gateway":              "SAFARICOM",
+				"active":               "false",
+				"activation_date__gte": "2022-08-01T00:00:00Z",
+				"activation_date__lte": "2022-08-31T00:00:00Z",
+				"ordering":             "-activation_date",
+				"page_size":            "100",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.QueryParams(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SubscriptionFilter.QueryParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSMSFilter_Validate(t *testing.T) {
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  SMSFilter
+		wantErr bool
+	}{
+		{
+			name:    "happy case: empty filter",
+			filter:  SMSFilter{},
+			wantErr: false,
+		},
+		{
+			name: "happy case: complete filter",
+			filter: SMSFilter{
+				Msisdn:        "+254722345678",
+				Sender:        "SIL",
+				State:         "DELIVERED",
+				Direction:     "OUTBOUND",
+				SMSType:       "PREMIUM",
+				CreatedAfter:  &yesterday,
+				CreatedBefore: &now,
+				Ordering:      "-created",
+				PageSize:      100,
+			},
+			wantErr: false,
+		},
+		{
+			name: "sad case: unknown ordering field",
+			filter: SMSFilter{
+				Ordering: "body",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: unknown state",
+			filter: SMSFilter{
+				State: "LOST",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: unknown direction",
+			filter: SMSFilter{
+				Direction: "SIDEWAYS",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: inverted creation date range",
+			filter: SMSFilter{
+				CreatedAfter:  &now,
+				CreatedBefore: &yesterday,
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: page size too large",
+			filter: SMSFilter{
+				PageSize: maxPageSize + 1,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("SMSFilter.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSMSFilter_QueryParams(t *testing.T) {
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 8, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter SMSFilter
+		want   map[string]string
+	}{
+		{
+			name:   "empty filter",
+			filter: SMSFilter{},
+			want:   map[string]string{},
+		},
+		{
+			name: "complete filter",
+			filter: SMSFilter{
+				Msisdn:        "+254722345678",
+				Sender:        "SIL",
+				State:         "DELIVERED",
+				Direction:     "OUTBOUND",
+				SMSType:       "PREMIUM",
+				Bulk:          "c8b8d9a4-5b0e-4c3a-9d2a-3b1f3f0c2f11",
+				CreatedAfter:  &from,
+				CreatedBefore: &to,
+				Ordering:      "-created",
+				PageSize:      100,
+			},
+			want: map[string]string{
+				"msisdn":       "+254722345678",
+				"sender":       "SIL",
+				"state":        "DELIVERED",
+				"direction":    "OUTBOUND",
+				"sms_type":     "PREMIUM",
+				"bulk":         "c8b8d9a4-5b0e-4c3a-9d2a-3b1f3f0c2f11",
+				"created__gte": "2022-08-01T00:00:00Z",
+				"created__lte": "2022-08-31T00:00:00Z",
+				"ordering":     "-created",
+				"page_size":    "100",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.QueryParams(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SMSFilter.QueryParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionFilter_normalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     SubscriptionFilter
+		wantMsisdn string
+		wantErr    bool
+	}{
+		{
+			name:       "happy case: national msisdn",
+			filter:     SubscriptionFilter{Msisdn: "0712 345 678"},
+			wantMsisdn: "+254712345678",
+		},
+		{
+			name:   "happy case: no msisdn",
+			filter: SubscriptionFilter{Offer: "01262626626"},
+		},
+		{
+			name:    "sad case: invalid msisdn",
+			filter:  SubscriptionFilter{Msisdn: "0712345"},
+			wantErr: true,
+		},
+		{
+			name:    "sad case: invalid ordering",
+			filter:  SubscriptionFilter{Msisdn: "0712345678", Ordering: "guid"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.normalize(*KenyaRegion())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SubscriptionFilter.normalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.Msisdn != tt.wantMsisdn {
+				t.Errorf("SubscriptionFilter.normalize() msisdn = %v, want %v", got.Msisdn, tt.wantMsisdn)
+			}
+		})
+	}
+}
+
+func TestSMSFilter_normalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     SMSFilter
+		wantMsisdn string
+		wantErr    bool
+	}{
+		{
+			name:       "happy case: country code without plus",
+			filter:     SMSFilter{Msisdn: "254712345678"},
+			wantMsisdn: "+254712345678",
+		},
+		{
+			name:   "happy case: no msisdn",
+			filter: SMSFilter{Sender: "SIL"},
+		},
+		{
+			name:    "sad case: invalid msisdn",
+			filter:  SMSFilter{Msisdn: "07one2345678"},
+			wantErr: true,
+		},
+		{
+			name:    "sad case: invalid state",
+			filter:  SMSFilter{Msisdn: "0712345678", State: "UNKNOWN"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.normalize(*KenyaRegion())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SMSFilter.normalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.Msisdn != tt.wantMsisdn {
+				t.Errorf("SMSFilter.normalize() msisdn = %v, want %v", got.Msisdn, tt.wantMsisdn)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package silcomms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the header used to send the idempotency key of a send request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// defaultIdempotencyTTL is how long the outcome of a send request is remembered
+var defaultIdempotencyTTL = time.Hour
+
+// SendOption customises a send request
+type SendOption func(*sendOptions)
+
+// sendOptions holds the customisations applied to a send request
+type sendOptions struct {
+	idempotencyKey string
+
+	// remember is set when the caller supplied the idempotency key and may retry the send with it
+	remember bool
+
+	// preflight drops invalid and duplicate bulk SMS recipients into the recipient report instead of failing
+	preflight       bool
+	recipientReport *RecipientReport
+}
+
+// WithIdempotencyKey sets the idempotency key of a send request.
+// Retrying a successful send with the same key returns the original response instead of sending again,
+// and a send with the same key that is still in flight is waited for.
+// Retrying a send whose outcome is unknown, e.g after a timeout, sends it again with the same key.
+// Reusing a key with a different message or recipients returns ErrIdempotencyKeyReused.
+// A random key is generated when none is provided. Sends with a generated key are not remembered
+func WithIdempotencyKey(key string) SendOption {
+	return func(o *sendOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// newSendOptions applies the provided options and generates an idempotency key when none was set
+func newSendOptions(opts []SendOption) *sendOptions {
+	options := &sendOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	options.remember = options.idempotencyKey != ""
+
+	if options.idempotencyKey == "" {
+		options.idempotencyKey = uuid.New().String()
+	}
+
+	return options
+}
+
+// idempotencyCache remembers send requests by idempotency key.
+// A key is reserved before its request is sent so that concurrent and retried sends with the same key
+// wait for and share the outcome of the first one instead of sending again
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+
+	// nextSweep is when expired entries are next purged
+	// Entries are checked for expiry when they are looked up so they are only swept once per ttl
+	nextSweep time.Time
+}
+
+// idempotencyEntry is a reserved key, the hash of the payload sent with it and its latest attempt
+type idempotencyEntry struct {
+	hash      string
+	attempt   *idempotencyAttempt
+	expiresAt time.Time
+}
+
+// idempotencyAttempt is a single send made with a key
+// Its outcome is set before done is closed and never changes afterwards
+type idempotencyAttempt struct {
+	done     chan struct{}
+	response interface{}
+	err      error
+	released bool
+}
+
+// finished reports whether the attempt has an outcome. It must be called with the cache lock held
+func (a *idempotencyAttempt) finished() bool {
+	select {
+	case <-a.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// newIdempotencyCache initializes a cache that remembers send requests for the provided duration
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:     ttl,
+		entries: map[string]*idempotencyEntry{},
+	}
+}
+
+// reserve claims a key for a request with the provided payload hash.
+// When the key is free, or the last request with it has an unknown outcome, the returned entry is owned by the caller
+// who must finish it with complete, markUnknown or release.
+// Otherwise reserve returns the remembered response, waiting for the request in flight with the key if there is one
+func (c *idempotencyCache) reserve(ctx context.Context, key, hash string, now time.Time) (*idempotencyEntry, interface{}, error) {
+	for {
+		c.mu.Lock()
+
+		c.sweep(now)
+
+		entry, ok := c.entries[key]
+		if ok && now.After(entry.expiresAt) {
+			delete(c.entries, key)
+
+			ok = false
+		}
+
+		if !ok {
+			entry = &idempotencyEntry{
+				hash:      hash,
+				attempt:   &idempotencyAttempt{done: make(chan struct{})},
+				expiresAt: now.Add(c.ttl),
+			}
+			c.entries[key] = entry
+
+			c.mu.Unlock()
+
+			return entry, nil, nil
+		}
+
+		if entry.hash != hash {
+			c.mu.Unlock()
+
+			return nil, nil, ErrIdempotencyKeyReused
+		}
+
+		attempt := entry.attempt
+
+		if attempt.finished() {
+			if attempt.err == nil {
+				c.mu.Unlock()
+
+				return nil, attempt.response, nil
+			}
+
+			// the API may not have received the last attempt, so the retry is sent again with the same key
+			// which lets the API deduplicate it
+			entry.attempt = &idempotencyAttempt{done: make(chan struct{})}
+			entry.expiresAt = now.Add(c.ttl)
+
+			c.mu.Unlock()
+
+			return entry, nil, nil
+		}
+
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-attempt.done:
+		}
+
+		// callers that waited share the outcome of the attempt they waited for
+		if !attempt.released {
+			return nil, attempt.response, attempt.err
+		}
+	}
+}
+
+// sweep purges expired entries at most once per ttl. It must be called with the cache lock held
+func (c *idempotencyCache) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.nextSweep = now.Add(c.ttl)
+}
+
+// complete remembers the response of a successful request
+func (c *idempotencyCache) complete(entry *idempotencyEntry, response interface{}, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.attempt.response = response
+	entry.expiresAt = now.Add(c.ttl)
+	close(entry.attempt.done)
+}
+
+// markUnknown records that a request may or may not have been acted on by the API
+// Callers waiting on it get the error while later retries with the key send the request again
+func (c *idempotencyCache) markUnknown(entry *idempotencyEntry, err error, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.attempt.err = err
+	entry.expiresAt = now.Add(c.ttl)
+	close(entry.attempt.done)
+}
+
+// release frees a key whose request was definitely not acted on so that it can be sent again
+func (c *idempotencyCache) release(key string, entry *idempotencyEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+
+	entry.attempt.released = true
+	close(entry.attempt.done)
+}
+
+// payloadHash fingerprints a send request payload so that a reused idempotency key can be detected
+func payloadHash(payload interface{}) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sendIdempotently makes a send request at most once per caller supplied idempotency key and decodes its response.
+// Failures after the request was sent return a SendOutcomeUnknownError. Retrying with the same key
+// sends the request again with the same idempotency key header so that the API can deduplicate it
+func sendIdempotently[T any](ctx context.Context, c *client, name, path string, payload interface{}, options *sendOptions, wantStatus int) (*T, error) {
+	if !options.remember {
+		// a generated key is never seen by the caller so the send cannot be retried with it
+		return send[T](ctx, c, name, path, payload, options.idempotencyKey, wantStatus)
+	}
+
+	hash, err := payloadHash(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", name, err)
+	}
+
+	cacheKey := path + options.idempotencyKey
+
+	entry, cached, err := c.idempotency.reserve(ctx, cacheKey, hash, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		result := cached.(T)
+
+		return &result, nil
+	}
+
+	result, err := send[T](ctx, c, name, path, payload, options.idempotencyKey, wantStatus)
+
+	var unknown *SendOutcomeUnknownError
+
+	switch {
+	case err == nil:
+		c.idempotency.complete(entry, *result, time.Now())
+	case errors.As(err, &unknown):
+		c.idempotency.markUnknown(entry, err, time.Now())
+	default:
+		c.idempotency.release(cacheKey, entry)
+	}
+
+	return result, err
+}
+
+// send makes a send request with the provided idempotency key and decodes its response.
+// Failures after the request was sent are returned as a SendOutcomeUnknownError
+func send[T any](ctx context.Context, c *client, name, path string, payload interface{}, idempotencyKey string, wantStatus int) (*T, error) {
+	unknown := func(err error) error {
+		return &SendOutcomeUnknownError{IdempotencyKey: idempotencyKey, Err: err}
+	}
+
+	response, err := c.MakeRequest(ctx, http.MethodPost, path, nil, payload, true, withHeader(IdempotencyKeyHeader, idempotencyKey))
+	if err != nil {
+		err = fmt.Errorf("failed to make %s request: %w", name, err)
+
+		var sentErr *requestSentError
+		if errors.As(err, &sentErr) {
+			return nil, unknown(err)
+		}
+
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != wantStatus {
+		err := fmt.Errorf("invalid %s response code: %w", name, newAPIError(response))
+
+		if response.StatusCode == http.StatusGatewayTimeout {
+			return nil, unknown(err)
+		}
+
+		return nil, err
+	}
+
+	resp, err := decodeResponse[T](response.Body)
+	if err != nil {
+		// the API accepted the request so it must not be sent again
+		return nil, unknown(fmt.Errorf("failed to decode %s api response: %w", name, err))
+	}
+
+	return &resp.Data, nil
+}
@@ -0,0 +1,282 @@
+package silcomms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func Test_idempotencyCache(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		key       string
+		hash      string
+		at        time.Time
+		wantOwned bool
+		wantErr   error
+	}{
+		{
+			name:      "happy case: remembered response",
+			key:       "key",
+			hash:      "hash",
+			at:        now.Add(time.Minute),
+			wantOwned: false,
+		},
+		{
+			name:      "happy case: expired response",
+			key:       "key",
+			hash:      "hash",
+			at:        now.Add(2 * time.Hour),
+			wantOwned: true,
+		},
+		{
+			name:      "happy case: unknown key",
+			key:       "unknown",
+			hash:      "other",
+			at:        now,
+			wantOwned: true,
+		},
+		{
+			name:    "sad case: key reused with a different payload",
+			key:     "key",
+			hash:    "other",
+			at:      now.Add(time.Minute),
+			wantErr: ErrIdempotencyKeyReused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newIdempotencyCache(time.Hour)
+
+			entry, _, err := c.reserve(context.Background(), "key", "hash", now)
+			if err != nil || entry == nil {
+				t.Fatalf("idempotencyCache.reserve() entry = %v, error = %v", entry, err)
+			}
+
+			c.complete(entry, "response", now)
+
+			got, response, err := c.reserve(context.Background(), tt.key, tt.hash, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("idempotencyCache.reserve() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if (got != nil) != tt.wantOwned {
+				t.Errorf("idempotencyCache.reserve() owned = %v, want %v", got != nil, tt.wantOwned)
+				return
+			}
+
+			if !tt.wantOwned && response != "response" {
+				t.Errorf("idempotencyCache.reserve() = %v, want %v", response, "response")
+			}
+		})
+	}
+}
+
+func Test_idempotencyCache_inFlight(t *testing.T) {
+	now := time.Now()
+	failure := errors.New("timeout")
+
+	tests := []struct {
+		name           string
+		finish         func(c *idempotencyCache, entry *idempotencyEntry)
+		wantWaiterErr  error
+		wantRetryOwned bool
+	}{
+		{
+			name: "happy case: waits for the response",
+			finish: func(c *idempotencyCache, entry *idempotencyEntry) {
+				c.complete(entry, "response", now)
+			},
+		},
+		{
+			name: "happy case: released key can be sent again",
+			finish: func(c *idempotencyCache, entry *idempotencyEntry) {
+				c.release("key", entry)
+			},
+			wantRetryOwned: true,
+		},
+		{
+			name: "sad case: unknown outcome is shared with waiters and sent again on retry",
+			finish: func(c *idempotencyCache, entry *idempotencyEntry) {
+				c.markUnknown(entry, failure, now)
+			},
+			wantWaiterErr:  failure,
+			wantRetryOwned: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newIdempotencyCache(time.Hour)
+
+			entry, _, err := c.reserve(context.Background(), "key", "hash", now)
+			if err != nil || entry == nil {
+				t.Fatalf("idempotencyCache.reserve() entry = %v, error = %v", entry, err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			if _, _, err := c.reserve(ctx, "key", "hash", now); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("idempotencyCache.reserve() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+
+			type result struct {
+				owned    *idempotencyEntry
+				response interface{}
+				err      error
+			}
+
+			waited := make(chan result, 1)
+
+			go func() {
+				owned, response, err := c.reserve(context.Background(), "key", "hash", now)
+				waited <- result{owned: owned, response: response, err: err}
+			}()
+
+			// let the waiter block on the request in flight before it finishes
+			time.Sleep(10 * time.Millisecond)
+			tt.finish(c, entry)
+
+			got := <-waited
+
+			if tt.wantRetryOwned && tt.wantWaiterErr == nil {
+				// a released key is claimed by the waiter itself
+				if got.owned == nil || got.err != nil {
+					t.Fatalf("idempotencyCache.reserve() waiter owned = %v, error = %v, want to own the key", got.owned != nil, got.err)
+				}
+
+				return
+			}
+
+			if !errors.Is(got.err, tt.wantWaiterErr) {
+				t.Fatalf("idempotencyCache.reserve() waiter error = %v, want %v", got.err, tt.wantWaiterErr)
+			}
+
+			if tt.wantWaiterErr == nil && got.response != "response" {
+				t.Errorf("idempotencyCache.reserve() waiter = %v, want %v", got.response, "response")
+			}
+
+			retry, _, err := c.reserve(context.Background(), "key", "hash", now)
+			if err != nil {
+				t.Fatalf("idempotencyCache.reserve() retry error = %v", err)
+			}
+
+			if (retry != nil) != tt.wantRetryOwned {
+				t.Errorf("idempotencyCache.reserve() retry owned = %v, want %v", retry != nil, tt.wantRetryOwned)
+			}
+		})
+	}
+}
+
+func Test_idempotencyCache_sweep(t *testing.T) {
+	now := time.Now()
+
+	c := newIdempotencyCache(time.Hour)
+
+	remember := func(key string, at time.Time) {
+		entry, _, err := c.reserve(context.Background(), key, "hash", at)
+		if err != nil || entry == nil {
+			t.Fatalf("idempotencyCache.reserve() entry = %v, error = %v", entry, err)
+		}
+
+		c.complete(entry, "response", at)
+	}
+
+	remember("a", now)
+	remember("b", now.Add(50*time.Minute))
+
+	// the first sweep is due an hour after the first reservation and purges a
+	remember("c", now.Add(70*time.Minute))
+
+	if _, ok := c.entries["a"]; ok || len(c.entries) != 2 {
+		t.Fatalf("idempotencyCache kept %d entries after the sweep, want 2", len(c.entries))
+	}
+
+	// b has expired but the next sweep is not due yet
+	remember("d", now.Add(115*time.Minute))
+
+	if len(c.entries) != 3 {
+		t.Fatalf("idempotencyCache kept %d entries between sweeps, want 3", len(c.entries))
+	}
+
+	if entry, _, _ := c.reserve(context.Background(), "b", "hash", now.Add(115*time.Minute)); entry == nil {
+		t.Errorf("idempotencyCache.reserve() returned an expired response")
+	}
+}
+
+func Test_sendIdempotently_generatedKeys(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	s := mustNewClient(testConfig, NewAuthServerServiceMock())
+	defer s.close(context.Background()) //nolint:errcheck
+
+	calls := 0
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), func(r *http.Request) (*http.Response, error) {
+		calls++
+
+		return httpmock.NewJsonResponse(http.StatusAccepted, APIResponse[BulkSMSResponse]{Status: StatusSuccess, Data: BulkSMSResponse{GUID: "guid"}})
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := sendIdempotently[BulkSMSResponse](context.Background(), s, "send bulk sms", "/v1/sms/bulk/", "payload", newSendOptions(nil), http.StatusAccepted); err != nil {
+			t.Fatalf("sendIdempotently() error = %v", err)
+		}
+	}
+
+	if calls != 3 || len(s.idempotency.entries) != 0 {
+		t.Errorf("sendIdempotently() made %d requests and remembered %d sends, want 3 and 0", calls, len(s.idempotency.entries))
+	}
+
+	if _, err := sendIdempotently[BulkSMSResponse](context.Background(), s, "send bulk sms", "/v1/sms/bulk/", "payload", newSendOptions([]SendOption{WithIdempotencyKey("key")}), http.StatusAccepted); err != nil {
+		t.Fatalf("sendIdempotently() error = %v", err)
+	}
+
+	if len(s.idempotency.entries) != 1 {
+		t.Errorf("sendIdempotently() remembered %d sends, want 1", len(s.idempotency.entries))
+	}
+}
+
+func Test_newSendOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []SendOption
+		want         string
+		wantRemember bool
+	}{
+		{
+			name:         "happy case: provided key",
+			opts:         []SendOption{WithIdempotencyKey("key")},
+			want:         "key",
+			wantRemember: true,
+		},
+		{
+			name: "happy case: generated key",
+			opts: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newSendOptions(tt.opts)
+			if got.idempotencyKey == "" || (tt.want != "" && got.idempotencyKey != tt.want) {
+				t.Errorf("newSendOptions() idempotency key = %v, want %v", got.idempotencyKey, tt.want)
+			}
+
+			if got.remember != tt.wantRemember {
+				t.Errorf("newSendOptions() remember = %v, want %v", got.remember, tt.wantRemember)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package silcomms
+
+import (
+	"fmt"
+	"io"
+)
+
+// ParseInboundSMS reads an inbound SMS from the body of a webhook request sent by SILCOMMS
+// The message may be sent as is or wrapped in the data field of an API response.
+// An error is returned when the payload is not an inbound message or has no sender or body
+func ParseInboundSMS(r io.Reader) (*InboundSMS, error) {
+	payload, err := readPayload(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var sms InboundSMS
+
+	err = unmarshalJSON(payload, &sms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode inbound sms data in payload: %w", err)
+	}
+
+	if sms.Direction != "" && sms.Direction != DirectionInbound {
+		return nil, fmt.Errorf("invalid inbound sms direction: %s", sms.Direction)
+	}
+
+	if sms.Msisdn == "" || sms.Body == "" {
+		return nil, fmt.Errorf("inbound sms payload must have a msisdn and body")
+	}
+
+	return &sms, nil
+}
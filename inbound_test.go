@@ -0,0 +1,69 @@
+package silcomms_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/savannahghi/silcomms"
+)
+
+func TestParseInboundSMS(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		wantMsisdn string
+		wantLinkID string
+		wantErr    bool
+	}{
+		{
+			name:       "happy case: bare payload",
+			payload:    `{"guid": "e7c0c3a4", "body": "YES", "msisdn": "+254722345678", "sender": "20475", "link_id": "123456", "direction": "INBOUND"}`,
+			wantMsisdn: "+254722345678",
+			wantLinkID: "123456",
+			wantErr:    false,
+		},
+		{
+			name:       "happy case: payload wrapped in api response",
+			payload:    `{"status": "success", "message": "inbound sms", "data": {"body": "YES", "msisdn": "+254722345678", "sender": "20475"}}`,
+			wantMsisdn: "+254722345678",
+			wantErr:    false,
+		},
+		{
+			name:    "sad case: invalid json",
+			payload: `{"body": `,
+			wantErr: true,
+		},
+		{
+			name:    "sad case: invalid field type",
+			payload: `{"body": "YES", "msisdn": 254722345678}`,
+			wantErr: true,
+		},
+		{
+			name:    "sad case: outbound message",
+			payload: `{"body": "YES", "msisdn": "+254722345678", "direction": "OUTBOUND"}`,
+			wantErr: true,
+		},
+		{
+			name:    "sad case: missing body",
+			payload: `{"msisdn": "+254722345678", "direction": "INBOUND"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := silcomms.ParseInboundSMS(strings.NewReader(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseInboundSMS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.Msisdn != tt.wantMsisdn || got.LinkID != tt.wantLinkID {
+				t.Errorf("ParseInboundSMS() = %+v, want msisdn %v and link id %v", got, tt.wantMsisdn, tt.wantLinkID)
+			}
+		})
+	}
+}
@@ -1,13 +1,21 @@
 package silcomms
 
+import (
+	"fmt"
+	"time"
+)
+
 // APIResponse is the base response from sil communications API
-type APIResponse struct {
-	Status  Status      `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+// Data is decoded into the model T of the response
+type APIResponse[T any] struct {
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Data    T      `json:"data,omitempty"`
 }
 
 // ResultsResponse is the base response from a paginated list of results
+//
+// Deprecated: use List which decodes the results into their model
 type ResultsResponse struct {
 	Count    int           `json:"count"`
 	Next     *string       `json:"next"`
@@ -18,9 +26,11 @@ type ResultsResponse struct {
 // TokenResponse is the data in the API response when logging in
 // The access token is used as the X-bearer token when making API requests
 // The refresh token is used to obtain a new access token when it expires
+// ExpiresIn is the access token lifetime in seconds when it is known
 type TokenResponse struct {
-	Refresh string `json:"refresh"`
-	Access  string `json:"access"`
+	Refresh   string `json:"refresh"`
+	Access    string `json:"access"`
+	ExpiresIn int    `json:"expires_in,omitempty"`
 }
 
 // ErrorMessage is the message in the ErrorResponse
@@ -37,40 +47,193 @@ type ErrorResponse struct {
 
 // BulkSMSResponse is the data in the API response that is returned after making a request to send bulk sms
 type BulkSMSResponse struct {
-	GUID       string   `json:"guid"`
-	Sender     string   `json:"sender"`
-	Message    string   `json:"message"`
-	Recipients []string `json:"recipients"`
-	State      string   `json:"state"`
-	SMS        []string `json:"sms"`
-	Created    string   `json:"created"`
-	Updated    string   `json:"updated"`
+	GUID       string       `json:"guid"`
+	Sender     string       `json:"sender"`
+	Message    string       `json:"message"`
+	Recipients []string     `json:"recipients"`
+	State      MessageState `json:"state"`
+	SMS        []string     `json:"sms"`
+	Created    time.Time    `json:"created"`
+	Updated    time.Time    `json:"updated"`
+}
+
+// UnmarshalJSON decodes a bulk SMS parsing its timestamps
+func (b *BulkSMSResponse) UnmarshalJSON(data []byte) error {
+	type bulkSMS BulkSMSResponse
+
+	aux := struct {
+		*bulkSMS
+		Created timestamp `json:"created"`
+		Updated timestamp `json:"updated"`
+	}{
+		bulkSMS: (*bulkSMS)(b),
+	}
+
+	if err := unmarshalJSON(data, &aux); err != nil {
+		return err
+	}
+
+	b.Created, b.Updated = aux.Created.Time, aux.Updated.Time
+
+	return nil
 }
 
 // PremiumSMSResponse is the response returned after making a request to SILCOMMS to send a premium SMS
 type PremiumSMSResponse struct {
-	GUID         string `json:"guid"`
-	Body         string `json:"body"`
-	Msisdn       string `json:"msisdn"`
-	SMSType      string `json:"sms_type"`
-	Gateway      string `json:"gateway"`
-	Carrier      string `json:"carrier"`
-	Subscription string `json:"subscription"`
-	Direction    string `json:"direction"`
-	State        string `json:"state"`
+	GUID         string       `json:"guid"`
+	Body         string       `json:"body"`
+	Msisdn       string       `json:"msisdn"`
+	SMSType      SMSType      `json:"sms_type"`
+	Gateway      string       `json:"gateway"`
+	Carrier      string       `json:"carrier"`
+	Subscription string       `json:"subscription"`
+	Direction    Direction    `json:"direction"`
+	State        MessageState `json:"state"`
+}
+
+// SMS is a single message sent through SILCOMMS including its delivery state
+// Bulk is the GUID of the bulk SMS that the message was sent as part of, if any
+type SMS struct {
+	GUID         string       `json:"guid"`
+	Body         string       `json:"body"`
+	Msisdn       string       `json:"msisdn"`
+	Sender       string       `json:"sender"`
+	SMSType      SMSType      `json:"sms_type"`
+	Gateway      string       `json:"gateway"`
+	Carrier      string       `json:"carrier"`
+	Subscription string       `json:"subscription"`
+	Bulk         string       `json:"bulk"`
+	Direction    Direction    `json:"direction"`
+	State        MessageState `json:"state"`
+	Created      time.Time    `json:"created"`
+	Updated      time.Time    `json:"updated"`
+}
+
+// UnmarshalJSON decodes an SMS parsing its timestamps
+func (s *SMS) UnmarshalJSON(data []byte) error {
+	type sms SMS
+
+	aux := struct {
+		*sms
+		Created timestamp `json:"created"`
+		Updated timestamp `json:"updated"`
+	}{
+		sms: (*sms)(s),
+	}
+
+	if err := unmarshalJSON(data, &aux); err != nil {
+		return err
+	}
+
+	s.Created, s.Updated = aux.Created.Time, aux.Updated.Time
+
+	return nil
+}
+
+// InboundSMS is a message sent by a subscriber to one of our short codes
+// Sender is the short code the message was sent to and Msisdn is the subscriber that sent it.
+// LinkID is set for premium messages and links a reply to the message it responds to
+type InboundSMS struct {
+	GUID         string       `json:"guid"`
+	Body         string       `json:"body"`
+	Msisdn       string       `json:"msisdn"`
+	Sender       string       `json:"sender"`
+	SMSType      SMSType      `json:"sms_type"`
+	Gateway      string       `json:"gateway"`
+	Carrier      string       `json:"carrier"`
+	Subscription string       `json:"subscription"`
+	LinkID       string       `json:"link_id"`
+	Direction    Direction    `json:"direction"`
+	State        MessageState `json:"state"`
+	Created      time.Time    `json:"created"`
+	Updated      time.Time    `json:"updated"`
+}
+
+// UnmarshalJSON decodes an inbound SMS parsing its timestamps
+func (s *InboundSMS) UnmarshalJSON(data []byte) error {
+	type inboundSMS InboundSMS
+
+	aux := struct {
+		*inboundSMS
+		Created timestamp `json:"created"`
+		Updated timestamp `json:"updated"`
+	}{
+		inboundSMS: (*inboundSMS)(s),
+	}
+
+	if err := unmarshalJSON(data, &aux); err != nil {
+		return err
+	}
+
+	s.Created, s.Updated = aux.Created.Time, aux.Updated.Time
+
+	return nil
+}
+
+// BulkSMSStatus is a bulk SMS and the SMS sent to each of its recipients with their delivery state
+type BulkSMSStatus struct {
+	Bulk     *BulkSMSResponse `json:"bulk"`
+	Messages []*SMS           `json:"messages"`
 }
 
 // Subscription represents the response that is returned when activating a subscription to an offer
 type Subscription struct {
-	GUID             string `json:"guid"`
-	Gateway          string `json:"gateway"`
-	Offer            string `json:"offer"`
-	Msisdn           string `json:"msisdn"`
-	LinkID           string `json:"link_id"`
-	ActivationDate   string `json:"activation_date"`
-	DeactivationDate any    `json:"deactivation_date"`
-	DeactivationType string `json:"deactivation_type"`
-	Sms              []any  `json:"sms"`
-	Created          string `json:"created"`
-	Updated          string `json:"updated"`
+	GUID             string           `json:"guid"`
+	Gateway          string           `json:"gateway"`
+	Offer            string           `json:"offer"`
+	Msisdn           string           `json:"msisdn"`
+	LinkID           string           `json:"link_id"`
+	ActivationDate   time.Time        `json:"activation_date"`
+	DeactivationDate *time.Time       `json:"deactivation_date"`
+	DeactivationType DeactivationType `json:"deactivation_type"`
+	Sms              []any            `json:"sms"`
+	Created          time.Time        `json:"created"`
+	Updated          time.Time        `json:"updated"`
+}
+
+// UnmarshalJSON decodes a subscription parsing its timestamps
+// An empty deactivation date leaves DeactivationDate nil
+func (s *Subscription) UnmarshalJSON(data []byte) error {
+	type subscription Subscription
+
+	aux := struct {
+		*subscription
+		ActivationDate   timestamp  `json:"activation_date"`
+		DeactivationDate *timestamp `json:"deactivation_date"`
+		Created          timestamp  `json:"created"`
+		Updated          timestamp  `json:"updated"`
+	}{
+		subscription: (*subscription)(s),
+	}
+
+	if err := unmarshalJSON(data, &aux); err != nil {
+		return err
+	}
+
+	s.ActivationDate, s.DeactivationDate = aux.ActivationDate.Time, aux.DeactivationDate.ptr()
+	s.Created, s.Updated = aux.Created.Time, aux.Updated.Time
+
+	return nil
+}
+
+// DeactivateSubscriptionInput identifies the subscription to deactivate and the reason for deactivating it
+// The subscription is identified by its GUID or by the offer and msisdn it was activated with
+type DeactivateSubscriptionInput struct {
+	GUID   string           `json:"guid,omitempty"`
+	Offer  string           `json:"offer,omitempty"`
+	Msisdn string           `json:"msisdn,omitempty"`
+	Reason DeactivationType `json:"reason,omitempty"`
+}
+
+// validate checks that the subscription to deactivate has been identified
+func (i DeactivateSubscriptionInput) validate() error {
+	if i.GUID == "" && (i.Offer == "" || i.Msisdn == "") {
+		return fmt.Errorf("either a subscription guid or both an offer and msisdn must be provided")
+	}
+
+	if i.Reason != "" && !i.Reason.IsValid() {
+		return fmt.Errorf("invalid deactivation reason %q", i.Reason)
+	}
+
+	return nil
 }
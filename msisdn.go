@@ -0,0 +1,217 @@
+package silcomms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidMSISDN is matched by errors caused by phone numbers that cannot be normalized
+var ErrInvalidMSISDN = errors.New("invalid msisdn")
+
+// maxE164Digits is the largest number of digits in an E.164 phone number
+const maxE164Digits = 15
+
+// MSISDN is a phone number in E.164 format e.g +254722345678
+type MSISDN string
+
+// String representation of msisdn
+func (m MSISDN) String() string {
+	return string(m)
+}
+
+// NationalNumber returns the digits of the phone number after the region's country code
+// It returns an empty string for phone numbers from other countries
+func (m MSISDN) NationalNumber(region Region) string {
+	prefix := "+" + region.CountryCode
+
+	if !strings.HasPrefix(string(m), prefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(string(m), prefix)
+}
+
+// Carrier returns the carrier that issued the phone number using the region's carrier prefixes
+// The longest matching prefix wins. It returns an empty string when the carrier is unknown
+func (m MSISDN) Carrier(region Region) string {
+	national := m.NationalNumber(region)
+	carrier, longest := "", 0
+
+	for prefix, name := range region.CarrierPrefixes {
+		if len(prefix) > longest && strings.HasPrefix(national, prefix) {
+			carrier, longest = name, len(prefix)
+		}
+	}
+
+	return carrier
+}
+
+// Region is the numbering plan used to normalize phone numbers written without a country code
+type Region struct {
+	// CountryCode is the calling code of the country without the + e.g 254
+	CountryCode string
+
+	// NationalNumberLength is the number of digits after the country code
+	NationalNumberLength int
+
+	// CarrierPrefixes maps the leading digits of national numbers to the carrier that issued them
+	CarrierPrefixes map[string]string
+}
+
+// KenyaRegion returns the numbering plan of Kenya which is used when none is configured
+func KenyaRegion() *Region {
+	prefixes := map[string]string{
+		"110": "SAFARICOM", "111": "SAFARICOM", "112": "SAFARICOM", "113": "SAFARICOM", "114": "SAFARICOM", "115": "SAFARICOM",
+		"100": "AIRTEL", "101": "AIRTEL", "102": "AIRTEL",
+		"70": "SAFARICOM", "71": "SAFARICOM", "72": "SAFARICOM", "79": "SAFARICOM",
+		"740": "SAFARICOM", "741": "SAFARICOM", "742": "SAFARICOM", "743": "SAFARICOM", "745": "SAFARICOM", "746": "SAFARICOM",
+		"748": "SAFARICOM", "757": "SAFARICOM", "758": "SAFARICOM", "759": "SAFARICOM", "768": "SAFARICOM", "769": "SAFARICOM",
+		"73": "AIRTEL", "78": "AIRTEL", "762": "AIRTEL",
+		"750": "AIRTEL", "751": "AIRTEL", "752": "AIRTEL", "753": "AIRTEL", "754": "AIRTEL", "755": "AIRTEL", "756": "AIRTEL",
+		"77":  "TELKOM",
+		"763": "EQUITEL", "764": "EQUITEL", "765": "EQUITEL", "766": "EQUITEL",
+		"747": "FAIBA",
+	}
+
+	return &Region{
+		CountryCode:          "254",
+		NationalNumberLength: 9,
+		CarrierPrefixes:      prefixes,
+	}
+}
+
+// validate checks that the region can be used to normalize phone numbers
+func (r Region) validate() error {
+	if r.CountryCode == "" || len(r.CountryCode) > 3 || !isDigits(r.CountryCode) {
+		return fmt.Errorf("invalid region country code %q, must be 1 to 3 digits", r.CountryCode)
+	}
+
+	if r.NationalNumberLength <= 0 || len(r.CountryCode)+r.NationalNumberLength > maxE164Digits {
+		return fmt.Errorf("invalid region national number length %d", r.NationalNumberLength)
+	}
+
+	return nil
+}
+
+// ParseMSISDN normalizes a phone number into E.164 format
+// Spaces, dashes, dots and brackets are ignored. Numbers written without a country code,
+// with or without the leading 0, are assumed to be from the provided region.
+// Numbers with a + or 00 international prefix from other countries are accepted as is
+func ParseMSISDN(raw string, region Region) (MSISDN, error) {
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')', '\t':
+			return -1
+		}
+
+		return r
+	}, raw)
+
+	international := false
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number, international = number[1:], true
+	case strings.HasPrefix(number, "00"):
+		number, international = number[2:], true
+	}
+
+	if number == "" || !isDigits(number) {
+		return "", fmt.Errorf("%w: %q must only contain digits", ErrInvalidMSISDN, raw)
+	}
+
+	nationalLength := region.NationalNumberLength
+	withCountryCode := len(region.CountryCode) + nationalLength
+
+	switch {
+	case strings.HasPrefix(number, region.CountryCode) && len(number) == withCountryCode:
+		return MSISDN("+" + number), nil
+	case international && strings.HasPrefix(number, region.CountryCode):
+		return "", fmt.Errorf("%w: %q must have %d digits after the country code", ErrInvalidMSISDN, raw, nationalLength)
+	case international && len(number) >= 8 && len(number) <= maxE164Digits:
+		return MSISDN("+" + number), nil
+	case international:
+		return "", fmt.Errorf("%w: %q must have between 8 and %d digits", ErrInvalidMSISDN, raw, maxE164Digits)
+	case strings.HasPrefix(number, "0") && len(number) == nationalLength+1:
+		return MSISDN("+" + region.CountryCode + number[1:]), nil
+	case !strings.HasPrefix(number, "0") && len(number) == nationalLength:
+		return MSISDN("+" + region.CountryCode + number), nil
+	}
+
+	return "", fmt.Errorf("%w: %q is not a +%s phone number", ErrInvalidMSISDN, raw, region.CountryCode)
+}
+
+// MSISDNError is the failure to normalize one of the phone numbers in a list
+type MSISDNError struct {
+	// Index is the position of the phone number in the list
+	Index int
+
+	// Input is the phone number as it was provided
+	Input string
+
+	Err error
+}
+
+func (e *MSISDNError) Error() string {
+	return fmt.Sprintf("recipient %d: %v", e.Index, e.Err)
+}
+
+func (e *MSISDNError) Unwrap() error {
+	return e.Err
+}
+
+// MSISDNErrors lists the phone numbers in a list that could not be normalized
+// It matches ErrInvalidMSISDN using errors.Is
+type MSISDNErrors []*MSISDNError
+
+func (e MSISDNErrors) Error() string {
+	messages := make([]string, 0, len(e))
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("%d invalid phone numbers: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Is matches ErrInvalidMSISDN
+func (e MSISDNErrors) Is(target error) bool {
+	return target == ErrInvalidMSISDN
+}
+
+// ParseMSISDNs normalizes a list of phone numbers into E.164 format
+// All the phone numbers are checked and every invalid one is reported in MSISDNErrors
+func ParseMSISDNs(raw []string, region Region) ([]MSISDN, error) {
+	msisdns := make([]MSISDN, 0, len(raw))
+
+	var errs MSISDNErrors
+
+	for i, number := range raw {
+		msisdn, err := ParseMSISDN(number, region)
+		if err != nil {
+			errs = append(errs, &MSISDNError{Index: i, Input: number, Err: err})
+
+			continue
+		}
+
+		msisdns = append(msisdns, msisdn)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return msisdns, nil
+}
+
+// isDigits returns true if the value only contains ASCII digits
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
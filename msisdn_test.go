@@ -0,0 +1,110 @@
+package silcomms_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/savannahghi/silcomms"
+)
+
+func TestParseMSISDN(t *testing.T) {
+	kenya := *silcomms.KenyaRegion()
+	tanzania := silcomms.Region{CountryCode: "255", NationalNumberLength: 9}
+
+	tests := []struct {
+		name    string
+		raw     string
+		region  silcomms.Region
+		want    silcomms.MSISDN
+		wantErr bool
+	}{
+		{name: "e164", raw: "+254712345678", region: kenya, want: "+254712345678"},
+		{name: "national with trunk prefix", raw: "0712345678", region: kenya, want: "+254712345678"},
+		{name: "national with spaces", raw: "0712 345 678", region: kenya, want: "+254712345678"},
+		{name: "e164 with dashes", raw: "+254-712-345-678", region: kenya, want: "+254712345678"},
+		{name: "country code without plus", raw: "254712345678", region: kenya, want: "+254712345678"},
+		{name: "international prefix", raw: "00254712345678", region: kenya, want: "+254712345678"},
+		{name: "national without trunk prefix", raw: "712345678", region: kenya, want: "+254712345678"},
+		{name: "new safaricom range", raw: "0110 345 678", region: kenya, want: "+254110345678"},
+		{name: "brackets and dots", raw: "(0712).345.678", region: kenya, want: "+254712345678"},
+		{name: "foreign e164", raw: "+14155552671", region: kenya, want: "+14155552671"},
+		{name: "configured region", raw: "0754 123 456", region: tanzania, want: "+255754123456"},
+		{name: "empty", raw: "", region: kenya, wantErr: true},
+		{name: "letters", raw: "07one2345678", region: kenya, wantErr: true},
+		{name: "too short", raw: "0712345", region: kenya, wantErr: true},
+		{name: "too long", raw: "07123456789", region: kenya, wantErr: true},
+		{name: "e164 with too many digits", raw: "+2547123456789", region: kenya, wantErr: true},
+		{name: "foreign e164 too short", raw: "+1415", region: kenya, wantErr: true},
+		{name: "plus only", raw: "+", region: kenya, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := silcomms.ParseMSISDN(tt.raw, tt.region)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMSISDN() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr && !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+				t.Errorf("ParseMSISDN() error = %v, want %v", err, silcomms.ErrInvalidMSISDN)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseMSISDN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMSISDN_Carrier(t *testing.T) {
+	kenya := *silcomms.KenyaRegion()
+
+	tests := []struct {
+		name   string
+		msisdn silcomms.MSISDN
+		want   string
+	}{
+		{name: "safaricom", msisdn: "+254722345678", want: "SAFARICOM"},
+		{name: "safaricom 01 range", msisdn: "+254110345678", want: "SAFARICOM"},
+		{name: "airtel", msisdn: "+254733345678", want: "AIRTEL"},
+		{name: "airtel within a safaricom block", msisdn: "+254762345678", want: "AIRTEL"},
+		{name: "telkom", msisdn: "+254772345678", want: "TELKOM"},
+		{name: "equitel", msisdn: "+254763345678", want: "EQUITEL"},
+		{name: "unknown prefix", msisdn: "+254202345678", want: ""},
+		{name: "foreign number", msisdn: "+14155552671", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msisdn.Carrier(kenya); got != tt.want {
+				t.Errorf("MSISDN.Carrier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMSISDNs(t *testing.T) {
+	kenya := *silcomms.KenyaRegion()
+
+	got, err := silcomms.ParseMSISDNs([]string{"0712345678", "+254733345678"}, kenya)
+	if err != nil {
+		t.Fatalf("ParseMSISDNs() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0] != "+254712345678" || got[1] != "+254733345678" {
+		t.Errorf("ParseMSISDNs() = %v, want the normalized phone numbers", got)
+	}
+
+	_, err = silcomms.ParseMSISDNs([]string{"0712345678", "not a number", "0712", "+254733345678"}, kenya)
+	if !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+		t.Fatalf("ParseMSISDNs() error = %v, want %v", err, silcomms.ErrInvalidMSISDN)
+	}
+
+	var errs silcomms.MSISDNErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("ParseMSISDNs() error = %v, want 2 invalid phone numbers", err)
+	}
+
+	if errs[0].Index != 1 || errs[0].Input != "not a number" || errs[1].Index != 2 || errs[1].Input != "0712" {
+		t.Errorf("ParseMSISDNs() errors = %v, want the second and third phone numbers", errs)
+	}
+}
@@ -0,0 +1,140 @@
+package silcomms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// List is a typed page from a paginated list of results
+// Next and Previous are cursors pointing to the adjacent pages, they are nil on the last and first page respectively
+type List[T any] struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []T     `json:"results"`
+}
+
+// PageSize returns the number of results in the page
+func (l *List[T]) PageSize() int {
+	return len(l.Results)
+}
+
+// HasNext returns true if there is a page after this one
+func (l *List[T]) HasNext() bool {
+	return l.Next != nil && *l.Next != ""
+}
+
+// HasPrevious returns true if there is a page before this one
+func (l *List[T]) HasPrevious() bool {
+	return l.Previous != nil && *l.Previous != ""
+}
+
+// getList fetches a page of results from the provided path or pagination link
+// name describes the listed resource in error messages
+func getList[T any](ctx context.Context, c *client, name, path string, queryParams map[string]string) (*List[T], error) {
+	response, err := c.MakeRequest(ctx, http.MethodGet, path, queryParams, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make get %s request: %w", name, err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid get %s response code: %w", name, newAPIError(response))
+	}
+
+	resp, err := decodeResponse[List[T]](response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode get %s api response: %w", name, err)
+	}
+
+	return &resp.Data, nil
+}
+
+// Pager iterates over the pages of a paginated list of results by following the Next cursors
+type Pager[T any] struct {
+	client      *client
+	name        string
+	path        string
+	queryParams map[string]string
+
+	page *List[T]
+	err  error
+	done bool
+}
+
+// newPager initializes a pager whose first page is fetched from the provided path and query params
+func newPager[T any](c *client, name, path string, queryParams map[string]string) *Pager[T] {
+	return &Pager[T]{
+		client:      c,
+		name:        name,
+		path:        path,
+		queryParams: queryParams,
+	}
+}
+
+// Next fetches the next page of results
+// It returns false when the pages are exhausted or fetching a page failed, Err reports the failure
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+
+	path, queryParams := p.path, p.queryParams
+
+	if p.page != nil {
+		if !p.page.HasNext() {
+			p.done = true
+
+			return false
+		}
+
+		path, queryParams = *p.page.Next, nil
+	}
+
+	page, err := getList[T](ctx, p.client, p.name, path, queryParams)
+	if err != nil {
+		p.stop(err)
+
+		return false
+	}
+
+	p.page = page
+
+	return true
+}
+
+// stop ends the iteration with the provided error
+func (p *Pager[T]) stop(err error) {
+	p.err = err
+	p.done = true
+}
+
+// Page returns the page fetched by the last call to Next
+func (p *Pager[T]) Page() *List[T] {
+	return p.page
+}
+
+// Err returns the error that stopped the pager, if any
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// ForEach calls fn for every result in the remaining pages
+// It stops when the pages are exhausted, the context is done or fn returns an error
+func (p *Pager[T]) ForEach(ctx context.Context, fn func(T) error) error {
+	for p.Next(ctx) {
+		for _, result := range p.page.Results {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := fn(result); err != nil {
+				return err
+			}
+		}
+	}
+
+	return p.Err()
+}
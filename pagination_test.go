@@ -0,0 +1,135 @@
+package silcomms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestList_cursors(t *testing.T) {
+	next := "https://comms.example.com/v1/sms/subscriptions/?page=2"
+	empty := ""
+
+	tests := []struct {
+		name         string
+		list         List[string]
+		wantSize     int
+		wantNext     bool
+		wantPrevious bool
+	}{
+		{
+			name: "first page",
+			list: List[string]{
+				Count:   3,
+				Next:    &next,
+				Results: []string{"a", "b"},
+			},
+			wantSize: 2,
+			wantNext: true,
+		},
+		{
+			name: "last page",
+			list: List[string]{
+				Count:    3,
+				Previous: &next,
+				Next:     &empty,
+				Results:  []string{"c"},
+			},
+			wantSize:     1,
+			wantPrevious: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.list.PageSize(); got != tt.wantSize {
+				t.Errorf("List.PageSize() = %v, want %v", got, tt.wantSize)
+			}
+
+			if got := tt.list.HasNext(); got != tt.wantNext {
+				t.Errorf("List.HasNext() = %v, want %v", got, tt.wantNext)
+			}
+
+			if got := tt.list.HasPrevious(); got != tt.wantPrevious {
+				t.Errorf("List.HasPrevious() = %v, want %v", got, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+func TestPager_Next(t *testing.T) {
+	tests := []struct {
+		name      string
+		failPage  string
+		wantPages int
+		wantErr   bool
+	}{
+		{
+			name:      "happy case: fetch every page",
+			failPage:  "none",
+			wantPages: 2,
+			wantErr:   false,
+		},
+		{
+			name:      "sad case: fetching a page fails",
+			failPage:  "2",
+			wantPages: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			s := mustNewClient(testConfig, NewAuthServerServiceMock())
+			defer s.close(context.Background()) //nolint:errcheck
+
+			httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", testConfig.BaseURL), func(r *http.Request) (*http.Response, error) {
+				page := r.URL.Query().Get("page")
+				if page == tt.failPage {
+					return httpmock.NewStringResponse(http.StatusBadRequest, ""), nil
+				}
+
+				data := map[string]interface{}{
+					"count":    2,
+					"next":     fmt.Sprintf("%s/v1/sms/subscriptions/?page=2", testConfig.BaseURL),
+					"previous": nil,
+					"results":  []map[string]interface{}{{"guid": "first"}},
+				}
+
+				if page == "2" {
+					data["next"] = nil
+					data["results"] = []map[string]interface{}{{"guid": "second"}}
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, APIResponse[any]{Status: StatusSuccess, Data: data})
+			})
+
+			pager := newPager[*Subscription](s, "subscriptions", "/v1/sms/subscriptions/", map[string]string{"offer": "01262626626"})
+
+			pages := 0
+			for pager.Next(context.Background()) {
+				pages++
+
+				if pager.Page().PageSize() != 1 {
+					t.Errorf("Pager.Page() size = %v, want 1", pager.Page().PageSize())
+				}
+			}
+
+			if pages != tt.wantPages {
+				t.Errorf("Pager.Next() fetched %v pages, want %v", pages, tt.wantPages)
+			}
+
+			if (pager.Err() != nil) != tt.wantErr {
+				t.Errorf("Pager.Err() = %v, wantErr %v", pager.Err(), tt.wantErr)
+			}
+
+			if pager.Next(context.Background()) {
+				t.Errorf("Pager.Next() = true after the pager stopped")
+			}
+		})
+	}
+}
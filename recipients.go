@@ -0,0 +1,82 @@
+package silcomms
+
+import "fmt"
+
+// RecipientReport is the outcome of normalizing and de-duplicating a list of recipients before sending
+type RecipientReport struct {
+	// Valid are the unique normalized recipients in the order they were first listed
+	Valid []MSISDN
+
+	// Duplicates are the recipients that were dropped because they normalize to an earlier recipient
+	Duplicates []string
+
+	// Invalid are the recipients that were dropped because they could not be normalized
+	Invalid MSISDNErrors
+}
+
+// PreflightRecipients normalizes and de-duplicates a list of recipients and partitions out the invalid ones
+// It never fails, the recipients that cannot be sent to are listed in the report
+func PreflightRecipients(recipients []string, region Region) *RecipientReport {
+	report := &RecipientReport{
+		Valid: make([]MSISDN, 0, len(recipients)),
+	}
+
+	seen := make(map[MSISDN]bool, len(recipients))
+
+	for i, recipient := range recipients {
+		msisdn, err := ParseMSISDN(recipient, region)
+		if err != nil {
+			report.Invalid = append(report.Invalid, &MSISDNError{Index: i, Input: recipient, Err: err})
+
+			continue
+		}
+
+		if seen[msisdn] {
+			report.Duplicates = append(report.Duplicates, recipient)
+
+			continue
+		}
+
+		seen[msisdn] = true
+		report.Valid = append(report.Valid, msisdn)
+	}
+
+	return report
+}
+
+// WithRecipientPreflight makes SendBulkSMS send only to the unique valid recipients
+// instead of rejecting the request when a recipient is invalid.
+// The dropped recipients are written to report when it is not nil.
+// The request still fails when none of the recipients is valid
+func WithRecipientPreflight(report *RecipientReport) SendOption {
+	return func(o *sendOptions) {
+		o.preflight = true
+		o.recipientReport = report
+	}
+}
+
+// bulkRecipients normalizes the recipients of a bulk SMS
+// All recipients must be valid unless the pre-flight step was requested
+func (l CommsLib) bulkRecipients(recipients []string, options *sendOptions) ([]MSISDN, error) {
+	region := *l.client.config.Region
+
+	if !options.preflight {
+		return ParseMSISDNs(recipients, region)
+	}
+
+	report := PreflightRecipients(recipients, region)
+
+	if options.recipientReport != nil {
+		*options.recipientReport = *report
+	}
+
+	if len(report.Valid) == 0 && len(report.Invalid) > 0 {
+		return nil, fmt.Errorf("no valid recipients: %w", report.Invalid)
+	}
+
+	if len(report.Valid) == 0 {
+		return nil, fmt.Errorf("no recipients provided")
+	}
+
+	return report.Valid, nil
+}
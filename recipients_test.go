@@ -0,0 +1,71 @@
+package silcomms_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/savannahghi/silcomms"
+)
+
+func TestPreflightRecipients(t *testing.T) {
+	kenya := *silcomms.KenyaRegion()
+
+	tests := []struct {
+		name           string
+		recipients     []string
+		wantValid      []silcomms.MSISDN
+		wantDuplicates []string
+		wantInvalid    []int
+	}{
+		{
+			name:       "no recipients",
+			recipients: []string{},
+			wantValid:  []silcomms.MSISDN{},
+		},
+		{
+			name:       "valid unique recipients",
+			recipients: []string{"0712345678", "+254733345678"},
+			wantValid:  []silcomms.MSISDN{"+254712345678", "+254733345678"},
+		},
+		{
+			name:           "duplicates written differently",
+			recipients:     []string{"0712 345 678", "+254712345678", "254-712-345-678", "0733345678"},
+			wantValid:      []silcomms.MSISDN{"+254712345678", "+254733345678"},
+			wantDuplicates: []string{"+254712345678", "254-712-345-678"},
+		},
+		{
+			name:        "junk rows",
+			recipients:  []string{"", "0712345678", "N/A", "0712"},
+			wantValid:   []silcomms.MSISDN{"+254712345678"},
+			wantInvalid: []int{0, 2, 3},
+		},
+		{
+			name:        "only invalid recipients",
+			recipients:  []string{"N/A", "0712"},
+			wantValid:   []silcomms.MSISDN{},
+			wantInvalid: []int{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := silcomms.PreflightRecipients(tt.recipients, kenya)
+
+			if !reflect.DeepEqual(got.Valid, tt.wantValid) {
+				t.Errorf("PreflightRecipients() valid = %v, want %v", got.Valid, tt.wantValid)
+			}
+
+			if !reflect.DeepEqual(got.Duplicates, tt.wantDuplicates) {
+				t.Errorf("PreflightRecipients() duplicates = %v, want %v", got.Duplicates, tt.wantDuplicates)
+			}
+
+			var invalid []int
+			for _, err := range got.Invalid {
+				invalid = append(invalid, err.Index)
+			}
+
+			if !reflect.DeepEqual(invalid, tt.wantInvalid) {
+				t.Errorf("PreflightRecipients() invalid = %v, want %v", invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package silcomms
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+var (
+	// defaultRetryAttempts is the number of attempts made for a request including the first one
+	defaultRetryAttempts = 3
+
+	// defaultRetryBackoff is the wait before the first retry. It doubles after every failed attempt
+	defaultRetryBackoff = 200 * time.Millisecond
+
+	// defaultMaxRetryBackoff caps the wait between retries including waits requested using Retry-After
+	defaultMaxRetryBackoff = 10 * time.Second
+)
+
+// RetryPolicy decides whether a failed attempt at making a request should be retried
+type RetryPolicy interface {
+	// Retry is called after every attempt with the response or the transport error.
+	// It returns how long to wait before the next attempt and whether one should be made
+	Retry(attempt int, method string, response *http.Response, err error) (time.Duration, bool)
+}
+
+// BackoffRetryPolicy retries transient failures using exponential backoff with jitter
+// Transient failures are timeouts, connection resets, 429 and 5xx gateway responses.
+// Only idempotent requests are retried unless RetryNonIdempotent is set
+type BackoffRetryPolicy struct {
+	// MaxAttempts is the number of attempts made including the first one. A value of 1 disables retries
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. It doubles after every failed attempt
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries. A Retry-After longer than this is not retried
+	MaxBackoff time.Duration
+
+	// RetryNonIdempotent enables retrying POST and PATCH requests
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+// It makes up to 3 attempts for idempotent requests only
+func DefaultRetryPolicy() BackoffRetryPolicy {
+	return BackoffRetryPolicy{
+		MaxAttempts:    defaultRetryAttempts,
+		InitialBackoff: defaultRetryBackoff,
+		MaxBackoff:     defaultMaxRetryBackoff,
+	}
+}
+
+// Retry implements RetryPolicy
+func (p BackoffRetryPolicy) Retry(attempt int, method string, response *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if !p.RetryNonIdempotent && !isIdempotent(method) {
+		return 0, false
+	}
+
+	if err != nil {
+		return p.backoff(attempt), isTransientError(err)
+	}
+
+	if !isTransientStatus(response.StatusCode) {
+		return 0, false
+	}
+
+	if wait, ok := retryAfter(response, time.Now()); ok {
+		return wait, wait <= p.MaxBackoff
+	}
+
+	return p.backoff(attempt), true
+}
+
+// backoff returns the exponential backoff for an attempt with jitter applied
+// The wait is picked at random between half and the full backoff
+func (p BackoffRetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	half := int64(backoff / 2)
+	if half <= 0 {
+		return backoff
+	}
+
+	return time.Duration(half + rand.Int63n(half+1)) //nolint:gosec
+}
+
+// isIdempotent returns true for HTTP methods that can safely be sent more than once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// isTransientStatus returns true for response status codes that are likely to succeed when retried
+func isTransientStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// isTransientError returns true for transport errors that are likely to succeed when retried
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// retryAfter reads the Retry-After response header which is either a number of seconds or a HTTP date
+func retryAfter(response *http.Response, now time.Time) (time.Duration, bool) {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,365 @@
+package silcomms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestBackoffRetryPolicy_Retry(t *testing.T) {
+	policy := BackoffRetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	withRetryAfter := func(statusCode int, value string) *http.Response {
+		response := &http.Response{StatusCode: statusCode, Header: http.Header{}}
+		response.Header.Set("Retry-After", value)
+
+		return response
+	}
+
+	tests := []struct {
+		name      string
+		policy    BackoffRetryPolicy
+		attempt   int
+		method    string
+		response  *http.Response
+		err       error
+		wantRetry bool
+		wantWait  time.Duration
+	}{
+		{
+			name:      "retry GET after service unavailable",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			response:  &http.Response{StatusCode: http.StatusServiceUnavailable},
+			wantRetry: true,
+		},
+		{
+			name:      "retry GET after a connection reset",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			err:       fmt.Errorf("read: %w", syscall.ECONNRESET),
+			wantRetry: true,
+		},
+		{
+			name:      "honor Retry-After seconds",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			response:  withRetryAfter(http.StatusTooManyRequests, "1"),
+			wantRetry: true,
+			wantWait:  time.Second,
+		},
+		{
+			name:      "do not retry when Retry-After exceeds the max backoff",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			response:  withRetryAfter(http.StatusTooManyRequests, "120"),
+			wantRetry: false,
+		},
+		{
+			name:      "do not retry successful responses",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			response:  &http.Response{StatusCode: http.StatusOK},
+			wantRetry: false,
+		},
+		{
+			name:      "do not retry client errors",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			response:  &http.Response{StatusCode: http.StatusBadRequest},
+			wantRetry: false,
+		},
+		{
+			name:      "do not retry cancelled requests",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodGet,
+			err:       context.Canceled,
+			wantRetry: false,
+		},
+		{
+			name:      "do not retry after the last attempt",
+			policy:    policy,
+			attempt:   3,
+			method:    http.MethodGet,
+			response:  &http.Response{StatusCode: http.StatusServiceUnavailable},
+			wantRetry: false,
+		},
+		{
+			name:      "do not retry POST by default",
+			policy:    policy,
+			attempt:   1,
+			method:    http.MethodPost,
+			response:  &http.Response{StatusCode: http.StatusServiceUnavailable},
+			wantRetry: false,
+		},
+		{
+			name: "retry POST when enabled",
+			policy: BackoffRetryPolicy{
+				MaxAttempts:        3,
+				InitialBackoff:     100 * time.Millisecond,
+				MaxBackoff:         time.Second,
+				RetryNonIdempotent: true,
+			},
+			attempt:   1,
+			method:    http.MethodPost,
+			response:  &http.Response{StatusCode: http.StatusServiceUnavailable},
+			wantRetry: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retry := tt.policy.Retry(tt.attempt, tt.method, tt.response, tt.err)
+			if retry != tt.wantRetry {
+				t.Errorf("BackoffRetryPolicy.Retry() retry = %v, want %v", retry, tt.wantRetry)
+				return
+			}
+
+			if tt.wantWait > 0 && wait != tt.wantWait {
+				t.Errorf("BackoffRetryPolicy.Retry() wait = %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestBackoffRetryPolicy_backoff(t *testing.T) {
+	policy := BackoffRetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		attempt int
+		max     time.Duration
+	}{
+		{
+			name:    "first retry",
+			attempt: 1,
+			max:     100 * time.Millisecond,
+		},
+		{
+			name:    "third retry",
+			attempt: 3,
+			max:     400 * time.Millisecond,
+		},
+		{
+			name:    "capped retry",
+			attempt: 8,
+			max:     time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.backoff(tt.attempt)
+			if got < tt.max/2 || got > tt.max {
+				t.Errorf("BackoffRetryPolicy.backoff() = %v, want between %v and %v", got, tt.max/2, tt.max)
+			}
+		})
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2022, 8, 4, 14, 11, 17, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "seconds",
+			value:  "5",
+			want:   5 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "http date",
+			value:  now.Add(30 * time.Second).Format(http.TimeFormat),
+			want:   30 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "http date in the past",
+			value:  now.Add(-30 * time.Second).Format(http.TimeFormat),
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "missing header",
+			value:  "",
+			wantOK: false,
+		},
+		{
+			name:   "invalid header",
+			value:  "soon",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			response.Header.Set("Retry-After", tt.value)
+
+			got, ok := retryAfter(response, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_client_MakeRequestRetries(t *testing.T) {
+	fastRetries := BackoffRetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		policy       RetryPolicy
+		responders   []httpmock.Responder
+		wantStatus   int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:   "happy case: GET succeeds after transient failures",
+			method: http.MethodGet,
+			policy: fastRetries,
+			responders: []httpmock.Responder{
+				httpmock.NewStringResponder(http.StatusServiceUnavailable, ""),
+				httpmock.NewErrorResponder(syscall.ECONNRESET),
+			},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		{
+			name:   "happy case: honor Retry-After on GET",
+			method: http.MethodGet,
+			policy: fastRetries,
+			responders: []httpmock.Responder{
+				func(_ *http.Request) (*http.Response, error) {
+					response := httpmock.NewStringResponse(http.StatusTooManyRequests, "")
+					response.Header.Set("Retry-After", "0")
+
+					return response, nil
+				},
+			},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:   "sad case: GET gives up after max attempts",
+			method: http.MethodGet,
+			policy: fastRetries,
+			responders: []httpmock.Responder{
+				httpmock.NewStringResponder(http.StatusBadGateway, ""),
+				httpmock.NewStringResponder(http.StatusBadGateway, ""),
+				httpmock.NewStringResponder(http.StatusBadGateway, ""),
+			},
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 3,
+		},
+		{
+			name:   "happy case: POST is not retried by default",
+			method: http.MethodPost,
+			policy: fastRetries,
+			responders: []httpmock.Responder{
+				httpmock.NewStringResponder(http.StatusServiceUnavailable, ""),
+			},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 1,
+		},
+		{
+			name:   "happy case: POST is retried when enabled",
+			method: http.MethodPost,
+			policy: BackoffRetryPolicy{
+				MaxAttempts:        3,
+				InitialBackoff:     time.Millisecond,
+				MaxBackoff:         10 * time.Millisecond,
+				RetryNonIdempotent: true,
+			},
+			responders: []httpmock.Responder{
+				httpmock.NewStringResponder(http.StatusServiceUnavailable, ""),
+			},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:   "sad case: transport error after max attempts",
+			method: http.MethodGet,
+			policy: fastRetries,
+			responders: []httpmock.Responder{
+				httpmock.NewErrorResponder(syscall.ECONNRESET),
+				httpmock.NewErrorResponder(syscall.ECONNRESET),
+				httpmock.NewErrorResponder(syscall.ECONNRESET),
+			},
+			wantErr:      true,
+			wantAttempts: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			config := testConfig
+			config.RetryPolicy = tt.policy
+
+			s := mustNewClient(config, NewAuthServerServiceMock())
+			defer s.close(context.Background()) //nolint:errcheck
+
+			attempts := 0
+
+			httpmock.RegisterResponder(tt.method, fmt.Sprintf("%s/v1/sms/bulk/", testConfig.BaseURL), func(r *http.Request) (*http.Response, error) {
+				attempts++
+
+				if attempts <= len(tt.responders) {
+					return tt.responders[attempts-1](r)
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			})
+
+			resp, err := s.MakeRequest(context.Background(), tt.method, "/v1/sms/bulk/", nil, map[string]string{}, true)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.MakeRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("client.MakeRequest() attempts = %v, want %v", attempts, tt.wantAttempts)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("client.MakeRequest() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
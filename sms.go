@@ -2,11 +2,11 @@ package silcomms
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-
-	"github.com/mitchellh/mapstructure"
+	"net/url"
 )
 
 // CommsLib is the SDK implementation for interacting with the sil communications API
@@ -14,6 +14,9 @@ type CommsLib struct {
 	client *client
 }
 
+// ensure the SDK can be released using the io.Closer interface
+var _ io.Closer = (*CommsLib)(nil)
+
 // APIErrorResponse is the representation of an error response
 type APIErrorResponse struct {
 	Status  string                 `json:"status"`
@@ -21,9 +24,9 @@ type APIErrorResponse struct {
 	Data    map[string]interface{} `json:"data"`
 }
 
-// NewSILCommsLib initializes a new implementation of the SIL Comms SDK
-func NewSILCommsLib(authServer AuthServerImpl) (*CommsLib, error) {
-	client, err := newClient(authServer)
+// NewCommsLib initializes a new implementation of the SIL Comms SDK using the provided configuration
+func NewCommsLib(config Config, authServer AuthServerImpl) (*CommsLib, error) {
+	client, err := newClient(config, authServer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize SIL Comms SMS SDK: %w", err)
 	}
@@ -35,9 +38,9 @@ func NewSILCommsLib(authServer AuthServerImpl) (*CommsLib, error) {
 	return l, nil
 }
 
-// MustNewSILCommsLib initializes a new implementation of the SIL Comms SDK
-func MustNewSILCommsLib(authServer AuthServerImpl) *CommsLib {
-	client := mustNewClient(authServer)
+// MustNewCommsLib initializes a new implementation of the SIL Comms SDK using the provided configuration
+func MustNewCommsLib(config Config, authServer AuthServerImpl) *CommsLib {
+	client := mustNewClient(config, authServer)
 
 	sdk := &CommsLib{
 		client: client,
@@ -46,179 +49,445 @@ func MustNewSILCommsLib(authServer AuthServerImpl) *CommsLib {
 	return sdk
 }
 
+// NewSILCommsLib initializes a new implementation of the SIL Comms SDK
+// The configuration is loaded from the SIL_COMMS_* environment variables
+func NewSILCommsLib(authServer AuthServerImpl) (*CommsLib, error) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize SIL Comms SMS SDK: %w", err)
+	}
+
+	return NewCommsLib(*config, authServer)
+}
+
+// MustNewSILCommsLib initializes a new implementation of the SIL Comms SDK
+// The configuration is loaded from the SIL_COMMS_* environment variables
+func MustNewSILCommsLib(authServer AuthServerImpl) *CommsLib {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	return MustNewCommsLib(*config, authServer)
+}
+
+// Shutdown stops the background token refresh routine and waits for it to exit or for the context to be done.
+// Requests made after shutdown fail with ErrClientClosed
+func (l CommsLib) Shutdown(ctx context.Context) error {
+	return l.client.close(ctx)
+}
+
+// Close stops the background token refresh routine.
+// Requests made after closing fail with ErrClientClosed
+func (l CommsLib) Close() error {
+	return l.client.close(context.Background())
+}
+
 // SendBulkSMS returns a 202 Accepted synchronous response while the API attempts to send the SMS in the background.
 // An asynchronous call is made to the app's sms_callback URL with a notification that shows the Bulk SMS status.
 // An asynchronous call is made to the app's sms_callback individually for each of the recipients with the SMS status.
 // message - message to be sent via the Bulk SMS
-// recipients - phone number(s) to receive the Bulk SMS
-func (l CommsLib) SendBulkSMS(ctx context.Context, message string, recipients []string, senderID string) (*BulkSMSResponse, error) {
+// recipients - phone number(s) to receive the Bulk SMS. They are normalized and every invalid one is reported in MSISDNErrors.
+// Use WithRecipientPreflight to drop invalid and duplicate recipients instead of failing
+// senderID - sender of the Bulk SMS. The configured sender ID is used when empty
+// opts - options such as the idempotency key used to safely retry the request
+func (l CommsLib) SendBulkSMS(ctx context.Context, message string, recipients []string, senderID string, opts ...SendOption) (*BulkSMSResponse, error) {
 	path := "/v1/sms/bulk/"
+
+	options := newSendOptions(opts)
+
+	msisdns, err := l.bulkRecipients(recipients, options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bulk sms recipients: %w", err)
+	}
+
+	if senderID == "" {
+		senderID = l.client.config.SenderID
+	}
+
 	payload := struct {
 		Sender     string   `json:"sender"`
 		Message    string   `json:"message"`
-		Recipients []string `json:"recipients"`
+		Recipients []MSISDN `json:"recipients"`
 	}{
 		Sender:     senderID,
 		Message:    message,
-		Recipients: recipients,
+		Recipients: msisdns,
 	}
 
-	response, err := l.client.MakeRequest(ctx, http.MethodPost, path, nil, payload, true)
+	return sendIdempotently[BulkSMSResponse](ctx, l.client, "send bulk sms", path, payload, options, http.StatusAccepted)
+}
+
+// SendPremiumSMS is used to send a premium SMS using SILCOMMS gateway.
+// message - message to be sent via the premium SMS.
+// msisdn - phone number to receive the premium SMS. It is normalized into E.164 format before sending
+// subscription - subscription/offer associated with the premium SMS.
+// opts - options such as the idempotency key used to safely retry the request
+func (l CommsLib) SendPremiumSMS(ctx context.Context, message, msisdn, subscription string, opts ...SendOption) (*PremiumSMSResponse, error) {
+	path := "/v1/sms/sms/"
+
+	options := newSendOptions(opts)
+
+	normalized, err := ParseMSISDN(msisdn, *l.client.config.Region)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make send bulk sms request: %w", err)
+		return nil, fmt.Errorf("invalid premium sms msisdn: %w", err)
 	}
 
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusAccepted {
-		var apiErr APIErrorResponse
-		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
-			return nil, fmt.Errorf("invalid send premium sms response code, got: %d", response.StatusCode)
-		}
+	payload := struct {
+		Body         string `json:"body"`
+		Msisdn       MSISDN `json:"msisdn"`
+		Subscription string `json:"subscription"`
+	}{
+		Body:         message,
+		Msisdn:       normalized,
+		Subscription: subscription,
+	}
 
-		err := fmt.Errorf("invalid send bulk sms response code, got: %d, error detail: %s", response.StatusCode, apiErr.Data)
+	return sendIdempotently[PremiumSMSResponse](ctx, l.client, "send premium sms", path, payload, options, http.StatusOK)
+}
 
-		return nil, err
+// ActivateSubscription is used activate a subscription to an offer on SILCOMMS.
+// msisdn - phone number to be to activate a subscription to an offer.
+// offer - offercode used to create a subscription.
+// activate - boolean value to determine whether activation should happen on SDP
+// Use CreateSubscription to get the created subscription
+func (l CommsLib) ActivateSubscription(ctx context.Context, offer string, msisdn string, activate bool) (bool, error) {
+	response, err := l.activateSubscription(ctx, offer, msisdn, activate)
+	if err != nil {
+		return false, err
 	}
 
-	var resp APIResponse
+	// the subscription is active once the API accepts the request, whatever the response body holds
+	response.Body.Close()
+
+	return true, nil
+}
 
-	err = json.NewDecoder(response.Body).Decode(&resp)
+// CreateSubscription activates a subscription to an offer on SILCOMMS and returns the created subscription.
+// The returned subscription GUID can be persisted to later deactivate the subscription.
+// msisdn - phone number to be to activate a subscription to an offer. It is normalized into E.164 format
+// offer - offercode used to create a subscription.
+// activate - boolean value to determine whether activation should happen on SDP
+func (l CommsLib) CreateSubscription(ctx context.Context, offer string, msisdn string, activate bool) (*Subscription, error) {
+	response, err := l.activateSubscription(ctx, offer, msisdn, activate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode send bulk sms api response: %w", err)
+		return nil, err
 	}
 
-	var bulkSMS BulkSMSResponse
+	defer response.Body.Close()
 
-	err = mapstructure.Decode(resp.Data, &bulkSMS)
+	resp, err := decodeResponse[Subscription](response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode send bulk sms data in api response: %w", err)
+		return nil, fmt.Errorf("failed to decode activate subscription api response: %w", err)
 	}
 
-	return &bulkSMS, nil
+	subscription := resp.Data
+
+	return &subscription, nil
 }
 
-// SendPremiumSMS is used to send a premium SMS using SILCOMMS gateway.
-// message - message to be sent via the premium SMS.
-// msisdn - phone number to receive the premium SMS.
-// subscription - subscription/offer associated with the premium SMS.
-func (l CommsLib) SendPremiumSMS(ctx context.Context, message, msisdn, subscription string) (*PremiumSMSResponse, error) {
-	path := "/v1/sms/sms/"
+// activateSubscription makes the activate subscription request and returns the response once the API has accepted it
+func (l CommsLib) activateSubscription(ctx context.Context, offer string, msisdn string, activate bool) (*http.Response, error) {
+	path := "/v1/sms/subscriptions/"
+
+	normalized, err := ParseMSISDN(msisdn, *l.client.config.Region)
+	if err != nil {
+		return nil, fmt.Errorf("invalid activate subscription msisdn: %w", err)
+	}
+
 	payload := struct {
-		Body         string `json:"body"`
-		Msisdn       string `json:"msisdn"`
-		Subscription string `json:"subscription"`
+		Offer    string `json:"offer"`
+		Msisdn   MSISDN `json:"msisdn"`
+		Activate bool   `json:"activate"`
 	}{
-		Body:         message,
-		Msisdn:       msisdn,
-		Subscription: subscription,
+		Offer:    offer,
+		Msisdn:   normalized,
+		Activate: activate,
 	}
 
 	response, err := l.client.MakeRequest(ctx, http.MethodPost, path, nil, payload, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make send premium sms request: %w", err)
+		return nil, fmt.Errorf("failed to make activate subscription request: %w", err)
 	}
 
-	defer response.Body.Close()
-
 	if response.StatusCode != http.StatusOK {
-		var apiErr APIErrorResponse
+		defer response.Body.Close()
 
-		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
-			return nil, fmt.Errorf("invalid send premium sms response code, got: %d", response.StatusCode)
+		return nil, fmt.Errorf("invalid activate subscription response code: %w", newAPIError(response))
+	}
+
+	return response, nil
+}
+
+// GetSubscriptions fetches subscriptions from SILCOMMs based on provided query params
+// Only the first page of results is returned. Prefer ListSubscriptions or ForEachSubscription which validate the filters
+// params - query params used to get a subscription to an offer.
+func (l CommsLib) GetSubscriptions(ctx context.Context, queryParams map[string]string) ([]*Subscription, error) {
+	if msisdn, ok := queryParams["msisdn"]; ok {
+		normalized, err := normalizeFilterMSISDN(msisdn, *l.client.config.Region)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subscription filter: %w", err)
+		}
+
+		params := make(map[string]string, len(queryParams))
+		for key, value := range queryParams {
+			params[key] = value
 		}
 
-		return nil, fmt.Errorf("invalid send premium sms response code, got: %d, error detail: %s", response.StatusCode, apiErr.Data)
+		params["msisdn"] = normalized
+		queryParams = params
+	}
+
+	page, err := getList[*Subscription](ctx, l.client, "subscriptions", "/v1/sms/subscriptions/", queryParams)
+	if err != nil {
+		return nil, err
 	}
 
-	var resp APIResponse
+	return page.Results, nil
+}
 
-	err = json.NewDecoder(response.Body).Decode(&resp)
+// ListSubscriptions fetches the first page of subscriptions matching the provided filter
+// The page holds the total count of matching subscriptions and the cursors of the next and previous pages
+// filter - narrows down the subscriptions. It is validated before the request is made
+func (l CommsLib) ListSubscriptions(ctx context.Context, filter SubscriptionFilter) (*List[*Subscription], error) {
+	filter, err := filter.normalize(*l.client.config.Region)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode send premium sms api response: %w", err)
+		return nil, fmt.Errorf("invalid subscription filter: %w", err)
 	}
 
-	var premiumSMS PremiumSMSResponse
+	return getList[*Subscription](ctx, l.client, "subscriptions", "/v1/sms/subscriptions/", filter.QueryParams())
+}
+
+// GetSubscriptionsPageAt fetches the page of subscriptions that a Next or Previous cursor points to
+// cursor - the Next or Previous value of a previously fetched page
+func (l CommsLib) GetSubscriptionsPageAt(ctx context.Context, cursor string) (*List[*Subscription], error) {
+	return getList[*Subscription](ctx, l.client, "subscriptions", cursor, nil)
+}
+
+// SubscriptionsPager returns a pager over every page of subscriptions matching the provided filter
+// filter - narrows down the subscriptions. An invalid filter is reported by the pager's Err
+func (l CommsLib) SubscriptionsPager(filter SubscriptionFilter) *Pager[*Subscription] {
+	filter, err := filter.normalize(*l.client.config.Region)
+
+	pager := newPager[*Subscription](l.client, "subscriptions", "/v1/sms/subscriptions/", filter.QueryParams())
 
-	err = mapstructure.Decode(resp.Data, &premiumSMS)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode send premium sms data in api response: %w", err)
+		pager.stop(fmt.Errorf("invalid subscription filter: %w", err))
 	}
 
-	return &premiumSMS, nil
+	return pager
 }
 
-// ActivateSubscription is used activate a subscription to an offer on SILCOMMS.
-// msisdn - phone number to be to activate a subscription to an offer.
-// offer - offercode used to create a subscription.
-// activate - boolean value to determine whether activation should happen on SDP
-func (l CommsLib) ActivateSubscription(ctx context.Context, offer string, msisdn string, activate bool) (bool, error) {
-	path := "/v1/sms/subscriptions/"
+// ForEachSubscription calls fn for every subscription matching the provided filter
+// Pages are fetched by following the Next cursor until they are exhausted, the context is done or fn returns an error
+// filter - narrows down the subscriptions. It is validated before the request is made
+func (l CommsLib) ForEachSubscription(ctx context.Context, filter SubscriptionFilter, fn func(*Subscription) error) error {
+	return l.SubscriptionsPager(filter).ForEach(ctx, fn)
+}
+
+// DeactivateSubscription ends a subscription to an offer on SILCOMMS and returns the updated subscription.
+// input - identifies the subscription using its GUID or the offer and msisdn it was activated with,
+// and the reason for deactivating it. The reason defaults to USER_INITIATED e.g when a patient opts out
+func (l CommsLib) DeactivateSubscription(ctx context.Context, input DeactivateSubscriptionInput) (*Subscription, error) {
+	if err := input.validate(); err != nil {
+		return nil, fmt.Errorf("invalid deactivate subscription input: %w", err)
+	}
+
+	guid := input.GUID
+
+	if guid == "" {
+		subscription, err := l.findActiveSubscription(ctx, input.Offer, input.Msisdn)
+		if err != nil {
+			return nil, err
+		}
+
+		guid = subscription.GUID
+	}
+
+	reason := input.Reason
+	if reason == "" {
+		reason = DeactivationTypeUserInitiated
+	}
+
+	path := fmt.Sprintf("/v1/sms/subscriptions/%s/deactivate/", url.PathEscape(guid))
 	payload := struct {
-		Offer    string `json:"offer"`
-		Msisdn   string `json:"msisdn"`
-		Activate bool   `json:"activate"`
+		DeactivationType DeactivationType `json:"deactivation_type"`
 	}{
-		Offer:    offer,
-		Msisdn:   msisdn,
-		Activate: activate,
+		DeactivationType: reason,
 	}
 
-	response, err := l.client.MakeRequest(ctx, http.MethodPost, path, nil, payload, true)
+	response, err := l.client.MakeRequest(ctx, http.MethodPatch, path, nil, payload, true)
 	if err != nil {
-		return false, fmt.Errorf("failed to make activate subscription request: %w", err)
+		return nil, fmt.Errorf("failed to make deactivate subscription request: %w", err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		var apiErr APIErrorResponse
-		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
-			return false, fmt.Errorf("invalid send premium sms response code, got: %d", response.StatusCode)
-		}
+		return nil, fmt.Errorf("invalid deactivate subscription response code: %w", newAPIError(response))
+	}
 
-		return false, fmt.Errorf("invalid activate subscription response code, got: %d, error detail: %s", response.StatusCode, apiErr.Data)
+	resp, err := decodeResponse[Subscription](response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode deactivate subscription api response: %w", err)
 	}
 
-	return true, nil
+	subscription := resp.Data
+
+	return &subscription, nil
 }
 
-// GetSubscriptions fetches subscriptions from SILCOMMs based on provided query params
-// params - query params used to get a subscription to an offer.
-func (l CommsLib) GetSubscriptions(ctx context.Context, queryParams map[string]string) ([]*Subscription, error) {
-	path := "/v1/sms/subscriptions/"
+// findActiveSubscription looks up the subscription of a msisdn to an offer that has not been deactivated
+func (l CommsLib) findActiveSubscription(ctx context.Context, offer, msisdn string) (*Subscription, error) {
+	normalized, err := ParseMSISDN(msisdn, *l.client.config.Region)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deactivate subscription msisdn: %w", err)
+	}
+
+	active := true
 
-	response, err := l.client.MakeRequest(ctx, http.MethodGet, path, queryParams, nil, true)
+	subscriptions, err := l.ListSubscriptions(ctx, SubscriptionFilter{
+		Offer:  offer,
+		Msisdn: normalized.String(),
+		Active: &active,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to make get subscriptions request: %w", err)
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions.Results {
+		if subscription.DeactivationDate == nil {
+			return subscription, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no active subscription to offer %s found for %s: %w", offer, msisdn, ErrNotFound)
+}
+
+// errAllRecipientsFound stops listing the SMS of a bulk SMS once every recipient's SMS is found
+var errAllRecipientsFound = errors.New("all bulk sms recipients found")
+
+// GetBulkSMS fetches a previously sent bulk SMS and the SMS sent to each of its recipients.
+// Each SMS holds the delivery state for its recipient which allows checking the progress of a bulk SMS
+// without waiting for the sms_callback notifications.
+// guid - the GUID returned when the bulk SMS was sent
+func (l CommsLib) GetBulkSMS(ctx context.Context, guid string) (*BulkSMSStatus, error) {
+	if guid == "" {
+		return nil, fmt.Errorf("a bulk sms guid must be provided")
+	}
+
+	path := fmt.Sprintf("/v1/sms/bulk/%s/", url.PathEscape(guid))
+
+	response, err := l.client.MakeRequest(ctx, http.MethodGet, path, nil, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make get bulk sms request: %w", err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid get subscriptions response code, got: %d", response.StatusCode)
+		return nil, fmt.Errorf("invalid get bulk sms response code: %w", newAPIError(response))
 	}
 
-	var resp APIResponse
+	resp, err := decodeResponse[BulkSMSResponse](response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode get bulk sms api response: %w", err)
+	}
 
-	err = json.NewDecoder(response.Body).Decode(&resp)
+	bulkSMS := resp.Data
+
+	messages := []*SMS{}
+
+	// the SMS listed by the bulk SMS are the only ones that belong to it
+	// Listing stops once all of them are found
+	pending := make(map[string]bool, len(bulkSMS.SMS))
+	for _, sms := range bulkSMS.SMS {
+		pending[sms] = true
+	}
+
+	err = l.ForEachSMS(ctx, SMSFilter{Bulk: guid}, func(sms *SMS) error {
+		// the bulk filter is not relied on since the API ignores filters it does not support
+		if sms.Bulk != guid || (len(bulkSMS.SMS) > 0 && !pending[sms.GUID]) {
+			return nil
+		}
+
+		messages = append(messages, sms)
+
+		delete(pending, sms.GUID)
+
+		if len(bulkSMS.SMS) > 0 && len(pending) == 0 {
+			return errAllRecipientsFound
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAllRecipientsFound) {
+		return nil, fmt.Errorf("failed to get bulk sms recipients: %w", err)
+	}
+
+	status := &BulkSMSStatus{
+		Bulk:     &bulkSMS,
+		Messages: messages,
+	}
+
+	return status, nil
+}
+
+// ListSMS fetches the first page of SMS matching the provided filter
+// The page holds the total count of matching SMS and the cursors of the next and previous pages
+// filter - narrows down the SMS. It is validated before the request is made
+func (l CommsLib) ListSMS(ctx context.Context, filter SMSFilter) (*List[*SMS], error) {
+	filter, err := filter.normalize(*l.client.config.Region)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode get subscriptions api response: %w", err)
+		return nil, fmt.Errorf("invalid sms filter: %w", err)
 	}
 
-	var resultResponse ResultsResponse
+	return getList[*SMS](ctx, l.client, "sms", "/v1/sms/sms/", filter.QueryParams())
+}
+
+// GetSMSPageAt fetches the page of SMS that a Next or Previous cursor points to
+// cursor - the Next or Previous value of a previously fetched page
+func (l CommsLib) GetSMSPageAt(ctx context.Context, cursor string) (*List[*SMS], error) {
+	return getList[*SMS](ctx, l.client, "sms", cursor, nil)
+}
+
+// SMSPager returns a pager over every page of SMS matching the provided filter
+// filter - narrows down the SMS. An invalid filter is reported by the pager's Err
+func (l CommsLib) SMSPager(filter SMSFilter) *Pager[*SMS] {
+	filter, err := filter.normalize(*l.client.config.Region)
+
+	pager := newPager[*SMS](l.client, "sms", "/v1/sms/sms/", filter.QueryParams())
 
-	err = mapstructure.Decode(resp.Data, &resultResponse)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode result response data in api response: %w", err)
+		pager.stop(fmt.Errorf("invalid sms filter: %w", err))
 	}
 
-	var subscriptions []*Subscription
+	return pager
+}
 
-	err = mapstructure.Decode(resultResponse.Results, &subscriptions)
+// ForEachSMS calls fn for every SMS matching the provided filter
+// Pages are fetched by following the Next cursor until they are exhausted, the context is done or fn returns an error
+// filter - narrows down the SMS. It is validated before the request is made
+func (l CommsLib) ForEachSMS(ctx context.Context, filter SMSFilter, fn func(*SMS) error) error {
+	return l.SMSPager(filter).ForEach(ctx, fn)
+}
+
+// ListInboundSMS fetches the first page of inbound SMS matching the provided filter
+// The filter's Direction is ignored and only messages sent by subscribers are listed
+// filter - narrows down the SMS. It is validated before the request is made
+func (l CommsLib) ListInboundSMS(ctx context.Context, filter SMSFilter) (*List[*InboundSMS], error) {
+	filter.Direction = DirectionInbound
+
+	filter, err := filter.normalize(*l.client.config.Region)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode subscriptions data in api response: %w", err)
+		return nil, fmt.Errorf("invalid sms filter: %w", err)
 	}
 
-	return subscriptions, nil
+	return getList[*InboundSMS](ctx, l.client, "inbound sms", "/v1/sms/sms/", filter.QueryParams())
+}
+
+// GetInboundSMSPageAt fetches the page of inbound SMS that a Next or Previous cursor points to
+// cursor - the Next or Previous value of a previously fetched page
+func (l CommsLib) GetInboundSMSPageAt(ctx context.Context, cursor string) (*List[*InboundSMS], error) {
+	return getList[*InboundSMS](ctx, l.client, "inbound sms", cursor, nil)
 }
@@ -2,9 +2,16 @@ package silcomms_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit"
 	"github.com/jarcoal/httpmock"
@@ -48,6 +55,18 @@ func (oc AuthServerServiceMock) RefreshToken(ctx context.Context, refreshToken s
 
 var authServer = NewAuthServerServiceMock()
 
+// kenyanPhone returns a random Kenyan mobile number written without the country code
+func kenyanPhone() string {
+	return fmt.Sprintf("07%08d", gofakeit.Number(0, 99999999))
+}
+
+var config = silcomms.Config{
+	BaseURL:  "https://comms.example.com",
+	Email:    gofakeit.Email(),
+	Password: gofakeit.Password(true, true, true, true, false, 12),
+	SenderID: "SIL",
+}
+
 func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 	type args struct {
 		ctx        context.Context
@@ -67,7 +86,7 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 				ctx:     context.Background(),
 				message: "This is a test",
 				recipients: []string{
-					gofakeit.Phone(),
+					kenyanPhone(),
 				},
 				senderID: "79079 SportPesa Jackpot",
 			},
@@ -79,7 +98,7 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 				ctx:     context.Background(),
 				message: "This is a test",
 				recipients: []string{
-					gofakeit.Phone(),
+					kenyanPhone(),
 				},
 				senderID: "79079 SportPesa Jackpot",
 			},
@@ -91,7 +110,7 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 				ctx:     context.Background(),
 				message: "This is a test",
 				recipients: []string{
-					gofakeit.Phone(),
+					kenyanPhone(),
 				},
 				senderID: "79079 SportPesa Jackpot",
 			},
@@ -103,7 +122,22 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 				ctx:     context.Background(),
 				message: "This is a test",
 				recipients: []string{
-					gofakeit.Phone(),
+					kenyanPhone(),
+				},
+				senderID: "79079 SportPesa Jackpot",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sad case: invalid recipients",
+			args: args{
+				ctx:     context.Background(),
+				message: "This is a test",
+				recipients: []string{
+					"0712 345 678",
+					"12345",
+					"+254-722-345-678",
+					"07123456789",
 				},
 				senderID: "79079 SportPesa Jackpot",
 			},
@@ -116,11 +150,11 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			l := silcomms.MustNewSILCommsLib(authServer)
+			l := silcomms.MustNewCommsLib(config, authServer)
 
 			if tt.name == "happy case: send bulk sms" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: silcomms.BulkSMSResponse{
@@ -133,13 +167,13 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 			}
 
 			if tt.name == "sad case: invalid status code" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
 					return httpmock.NewJsonResponse(http.StatusUnauthorized, nil)
 				})
 			}
 
 			if tt.name == "sad case: invalid API response" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
 					resp := map[string]interface{}{
 						"status":  1234,
 						"message": 1234,
@@ -150,8 +184,8 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 			}
 
 			if tt.name == "sad case: invalid bulk SMS data response" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: map[string]interface{}{
@@ -172,6 +206,24 @@ func TestSILCommsLib_SendBulkSMS(t *testing.T) {
 				return
 			}
 
+			if tt.name == "sad case: invalid status code" {
+				var apiErr *silcomms.APIError
+				if !errors.As(err, &apiErr) || !errors.Is(err, silcomms.ErrUnauthorized) {
+					t.Errorf("SILCommsLib.SendBulkSMS() error = %v, want an unauthorized API error", err)
+
+					return
+				}
+			}
+
+			if tt.name == "sad case: invalid recipients" {
+				var msisdnErrs silcomms.MSISDNErrors
+				if !errors.As(err, &msisdnErrs) || len(msisdnErrs) != 2 || msisdnErrs[0].Index != 1 || msisdnErrs[1].Index != 3 {
+					t.Errorf("SILCommsLib.SendBulkSMS() error = %v, want the second and fourth recipients reported", err)
+
+					return
+				}
+			}
+
 			if !tt.wantErr && got == nil {
 				t.Errorf("SILCommsLib.SendBulkSMS() expected response not to be nil for %v", tt.name)
 
@@ -199,7 +251,7 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			args: args{
 				ctx:          context.Background(),
 				message:      "test premium sms",
-				msisdn:       gofakeit.Phone(),
+				msisdn:       kenyanPhone(),
 				subscription: "01262626626",
 			},
 			wantErr: false,
@@ -209,7 +261,7 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			args: args{
 				ctx:          context.Background(),
 				message:      "test premium sms",
-				msisdn:       gofakeit.Phone(),
+				msisdn:       kenyanPhone(),
 				subscription: "01262626626",
 			},
 			wantErr: true,
@@ -219,7 +271,7 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			args: args{
 				ctx:          context.Background(),
 				message:      "test premium sms",
-				msisdn:       gofakeit.Phone(),
+				msisdn:       kenyanPhone(),
 				subscription: "01262626626",
 			},
 			wantErr: true,
@@ -229,7 +281,17 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			args: args{
 				ctx:          context.Background(),
 				message:      "test premium sms",
-				msisdn:       gofakeit.Phone(),
+				msisdn:       kenyanPhone(),
+				subscription: "01262626626",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: invalid msisdn",
+			args: args{
+				ctx:          context.Background(),
+				message:      "test premium sms",
+				msisdn:       "0712-345",
 				subscription: "01262626626",
 			},
 			wantErr: true,
@@ -241,11 +303,11 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			l := silcomms.MustNewSILCommsLib(authServer)
+			l := silcomms.MustNewCommsLib(config, authServer)
 
 			if tt.name == "Happy case: send premium sms" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: silcomms.PremiumSMSResponse{
@@ -258,13 +320,13 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			}
 
 			if tt.name == "Sad case: invalid status code" { //nolint: goconst
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
 					return httpmock.NewJsonResponse(http.StatusUnauthorized, nil)
 				})
 			}
 
 			if tt.name == "Sad case: invalid API response" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
 					resp := map[string]interface{}{
 						"status":  1234,
 						"message": 1234,
@@ -275,8 +337,8 @@ func TestSILCommsLib_SendPremiumSMS(t *testing.T) {
 			}
 
 			if tt.name == "Sad case: invalid premium SMS data response" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: map[string]interface{}{
@@ -326,7 +388,7 @@ func TestSILCommsLib_ActivateSubscription(t *testing.T) {
 			args: args{
 				ctx:    ctx,
 				offer:  "01262626626",
-				msisdn: gofakeit.Phone(),
+				msisdn: kenyanPhone(),
 			},
 			wantErr: false,
 		},
@@ -335,17 +397,26 @@ func TestSILCommsLib_ActivateSubscription(t *testing.T) {
 			args: args{
 				ctx:      ctx,
 				offer:    "01262626626",
-				msisdn:   gofakeit.Phone(),
+				msisdn:   kenyanPhone(),
 				activate: false,
 			},
 			wantErr: false,
 		},
+		{
+			name: "Happy case: undecodable response body",
+			args: args{
+				ctx:    ctx,
+				offer:  "01262626626",
+				msisdn: kenyanPhone(),
+			},
+			wantErr: false,
+		},
 		{
 			name: "Sad case: invalid status code",
 			args: args{
 				ctx:    ctx,
 				offer:  "01262626626",
-				msisdn: gofakeit.Phone(),
+				msisdn: kenyanPhone(),
 			},
 			wantErr: true,
 		},
@@ -356,11 +427,11 @@ func TestSILCommsLib_ActivateSubscription(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			l := silcomms.MustNewSILCommsLib(authServer)
+			l := silcomms.MustNewCommsLib(config, authServer)
 
 			if tt.name == "Happy case: activate subscription" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: map[string]interface{}{
@@ -375,8 +446,8 @@ func TestSILCommsLib_ActivateSubscription(t *testing.T) {
 			}
 
 			if tt.name == "Happy case: activate subscription bypass sdp" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: map[string]interface{}{
@@ -390,8 +461,14 @@ func TestSILCommsLib_ActivateSubscription(t *testing.T) {
 				})
 			}
 
+			if tt.name == "Happy case: undecodable response body" {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewStringResponse(http.StatusOK, "OK"), nil
+				})
+			}
+
 			if tt.name == "Sad case: invalid status code" {
-				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
 					return httpmock.NewJsonResponse(http.StatusUnauthorized, nil)
 				})
 			}
@@ -428,7 +505,7 @@ func TestSILCommsLib_GetSubscriptions(t *testing.T) {
 			args: args{
 				ctx: ctx,
 				queryParams: map[string]string{
-					"msisdn": gofakeit.Phone(),
+					"msisdn": kenyanPhone(),
 					"offer":  "01262626626",
 				},
 			},
@@ -439,7 +516,7 @@ func TestSILCommsLib_GetSubscriptions(t *testing.T) {
 			args: args{
 				ctx: ctx,
 				queryParams: map[string]string{
-					"msisdn": gofakeit.Phone(),
+					"msisdn": kenyanPhone(),
 					"offer":  "01262626626",
 				},
 			},
@@ -452,11 +529,11 @@ func TestSILCommsLib_GetSubscriptions(t *testing.T) {
 			httpmock.Activate()
 			defer httpmock.DeactivateAndReset()
 
-			l := silcomms.MustNewSILCommsLib(authServer)
+			l := silcomms.MustNewCommsLib(config, authServer)
 
 			if tt.name == "Happy case: get subscription" {
-				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
-					resp := silcomms.APIResponse{
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
 						Status:  silcomms.StatusSuccess,
 						Message: "success",
 						Data: map[string]interface{}{
@@ -486,7 +563,7 @@ func TestSILCommsLib_GetSubscriptions(t *testing.T) {
 			}
 
 			if tt.name == "Sad case: invalid status code" {
-				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", silcomms.BaseURL), func(_ *http.Request) (*http.Response, error) {
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
 					return httpmock.NewJsonResponse(http.StatusUnauthorized, nil)
 				})
 			}
@@ -506,3 +583,1298 @@ func TestSILCommsLib_GetSubscriptions(t *testing.T) {
 		})
 	}
 }
+
+func TestCommsLib_Close(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "happy case: close the sdk",
+			wantErr: false,
+		},
+		{
+			name:    "happy case: shutdown the sdk",
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := silcomms.MustNewCommsLib(config, NewAuthServerServiceMock())
+
+			var err error
+
+			if tt.name == "happy case: close the sdk" {
+				err = l.Close()
+			}
+
+			if tt.name == "happy case: shutdown the sdk" {
+				err = l.Shutdown(context.Background())
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CommsLib.Close() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			_, err = l.SendBulkSMS(context.Background(), "This is a test", []string{kenyanPhone()}, "")
+			if !errors.Is(err, silcomms.ErrClientClosed) {
+				t.Errorf("CommsLib.SendBulkSMS() error = %v, want %v", err, silcomms.ErrClientClosed)
+			}
+		})
+	}
+}
+
+func TestCommsLib_SendBulkSMSIdempotency(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, NewAuthServerServiceMock())
+	defer l.Close() //nolint:errcheck
+
+	keys := []string{}
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		keys = append(keys, r.Header.Get(silcomms.IdempotencyKeyHeader))
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: silcomms.BulkSMSResponse{
+				GUID: gofakeit.UUID(),
+			},
+		}
+
+		return httpmock.NewJsonResponse(http.StatusAccepted, resp)
+	})
+
+	ctx := context.Background()
+	recipients := []string{kenyanPhone()}
+
+	first, err := l.SendBulkSMS(ctx, "This is a test", recipients, "", silcomms.WithIdempotencyKey("reminder-1"))
+	if err != nil {
+		t.Fatalf("SILCommsLib.SendBulkSMS() error = %v", err)
+	}
+
+	retried, err := l.SendBulkSMS(ctx, "This is a test", recipients, "", silcomms.WithIdempotencyKey("reminder-1"))
+	if err != nil {
+		t.Fatalf("SILCommsLib.SendBulkSMS() error = %v", err)
+	}
+
+	if retried.GUID != first.GUID {
+		t.Errorf("SILCommsLib.SendBulkSMS() retried GUID = %v, want %v", retried.GUID, first.GUID)
+	}
+
+	if _, err := l.SendBulkSMS(ctx, "This is a test", recipients, ""); err != nil {
+		t.Fatalf("SILCommsLib.SendBulkSMS() error = %v", err)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("SILCommsLib.SendBulkSMS() made %d requests, want 2", len(keys))
+	}
+
+	if keys[0] != "reminder-1" || keys[1] == "" || keys[1] == keys[0] {
+		t.Errorf("SILCommsLib.SendBulkSMS() sent idempotency keys %v", keys)
+	}
+}
+
+func TestCommsLib_SendPremiumSMSIdempotency(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, NewAuthServerServiceMock())
+	defer l.Close() //nolint:errcheck
+
+	calls := 0
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		calls++
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: silcomms.PremiumSMSResponse{
+				GUID: gofakeit.UUID(),
+			},
+		}
+
+		return httpmock.NewJsonResponse(http.StatusOK, resp)
+	})
+
+	ctx := context.Background()
+	msisdn := kenyanPhone()
+
+	for i := 0; i < 3; i++ {
+		if _, err := l.SendPremiumSMS(ctx, "test premium sms", msisdn, "01262626626", silcomms.WithIdempotencyKey("check-in-1")); err != nil {
+			t.Fatalf("SILCommsLib.SendPremiumSMS() error = %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("SILCommsLib.SendPremiumSMS() made %d requests, want 1", calls)
+	}
+}
+
+func TestCommsLib_SendBulkSMSIdempotencyOutcome(t *testing.T) {
+	type args struct {
+		retryMessage string
+	}
+
+	tests := []struct {
+		name      string
+		args      args
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name: "sad case: retry after a timeout is sent again with the same key",
+			args: args{
+				retryMessage: "This is a test",
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "sad case: retry after a rejected request is sent again",
+			args: args{
+				retryMessage: "This is a test",
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "sad case: retry after a failed connection is sent again",
+			args: args{
+				retryMessage: "This is a test",
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "sad case: key reused with a different message",
+			args: args{
+				retryMessage: "This is another test",
+			},
+			wantErr:   silcomms.ErrIdempotencyKeyReused,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			l := silcomms.MustNewCommsLib(config, NewAuthServerServiceMock())
+			defer l.Close() //nolint:errcheck
+
+			var (
+				calls int32
+				mu    sync.Mutex
+				keys  []string
+			)
+
+			name := tt.name
+
+			httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+				call := atomic.AddInt32(&calls, 1)
+
+				mu.Lock()
+				keys = append(keys, r.Header.Get(silcomms.IdempotencyKeyHeader))
+				mu.Unlock()
+
+				if call == 1 {
+					switch name {
+					case "sad case: retry after a timeout is sent again with the same key":
+						time.Sleep(100 * time.Millisecond)
+					case "sad case: retry after a rejected request is sent again":
+						return httpmock.NewJsonResponse(http.StatusBadRequest, silcomms.ErrorResponse{Detail: "invalid sender"})
+					case "sad case: retry after a failed connection is sent again":
+						return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+					}
+				}
+
+				resp := silcomms.APIResponse[any]{
+					Status:  silcomms.StatusSuccess,
+					Message: "success",
+					Data: silcomms.BulkSMSResponse{
+						GUID: gofakeit.UUID(),
+					},
+				}
+
+				return httpmock.NewJsonResponse(http.StatusAccepted, resp)
+			})
+
+			recipients := []string{kenyanPhone()}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			_, err := l.SendBulkSMS(ctx, "This is a test", recipients, "", silcomms.WithIdempotencyKey("reminder-1"))
+
+			var unknown *silcomms.SendOutcomeUnknownError
+			if name == "sad case: retry after a timeout is sent again with the same key" && (!errors.As(err, &unknown) || unknown.IdempotencyKey != "reminder-1") {
+				t.Fatalf("SILCommsLib.SendBulkSMS() error = %v, want %v", err, silcomms.ErrSendOutcomeUnknown)
+			}
+
+			if name == "sad case: retry after a failed connection is sent again" && (err == nil || errors.Is(err, silcomms.ErrSendOutcomeUnknown)) {
+				t.Fatalf("SILCommsLib.SendBulkSMS() error = %v, want a connection error", err)
+			}
+
+			_, err = l.SendBulkSMS(context.Background(), tt.args.retryMessage, recipients, "", silcomms.WithIdempotencyKey("reminder-1"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SILCommsLib.SendBulkSMS() retry error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("SILCommsLib.SendBulkSMS() made %d requests, want %d", got, tt.wantCalls)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, key := range keys {
+				if key != "reminder-1" {
+					t.Errorf("SILCommsLib.SendBulkSMS() sent idempotency keys %v, want reminder-1", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestCommsLib_SendPremiumSMSConcurrentIdempotency(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, NewAuthServerServiceMock())
+	defer l.Close() //nolint:errcheck
+
+	var calls int32
+
+	release := make(chan struct{})
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+
+		<-release
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: silcomms.PremiumSMSResponse{
+				GUID: gofakeit.UUID(),
+			},
+		}
+
+		return httpmock.NewJsonResponse(http.StatusOK, resp)
+	})
+
+	msisdn := kenyanPhone()
+
+	const senders = 5
+
+	guids := make(chan string, senders)
+	errs := make(chan error, senders)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sms, err := l.SendPremiumSMS(context.Background(), "test premium sms", msisdn, "01262626626", silcomms.WithIdempotencyKey("check-in-1"))
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			guids <- sms.GUID
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(guids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("SILCommsLib.SendPremiumSMS() error = %v", err)
+	}
+
+	seen := map[string]bool{}
+	for guid := range guids {
+		seen[guid] = true
+	}
+
+	if len(seen) != 1 {
+		t.Errorf("SILCommsLib.SendPremiumSMS() returned %d different responses, want 1", len(seen))
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("SILCommsLib.SendPremiumSMS() made %d requests, want 1", got)
+	}
+}
+
+func TestSILCommsLib_DeactivateSubscription(t *testing.T) {
+	ctx := context.Background()
+	guid := gofakeit.UUID()
+
+	type args struct {
+		ctx   context.Context
+		input silcomms.DeactivateSubscriptionInput
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "Happy case: deactivate subscription by guid",
+			args: args{
+				ctx: ctx,
+				input: silcomms.DeactivateSubscriptionInput{
+					GUID:   guid,
+					Reason: "USER_INITIATED",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Happy case: deactivate subscription by offer and msisdn",
+			args: args{
+				ctx: ctx,
+				input: silcomms.DeactivateSubscriptionInput{
+					Offer:  "01262626626",
+					Msisdn: "+254722345678",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Sad case: missing subscription identifier",
+			args: args{
+				ctx: ctx,
+				input: silcomms.DeactivateSubscriptionInput{
+					Offer: "01262626626",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: invalid deactivation reason",
+			args: args{
+				ctx: ctx,
+				input: silcomms.DeactivateSubscriptionInput{
+					GUID:   guid,
+					Reason: "OPTED_OUT",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: no active subscription",
+			args: args{
+				ctx: ctx,
+				input: silcomms.DeactivateSubscriptionInput{
+					Offer:  "01262626626",
+					Msisdn: "+254722345678",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: invalid status code",
+			args: args{
+				ctx: ctx,
+				input: silcomms.DeactivateSubscriptionInput{
+					GUID: guid,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			l := silcomms.MustNewCommsLib(config, authServer)
+
+			subscriptions := func(deactivationDate interface{}) httpmock.Responder {
+				return func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"count":    1,
+							"next":     nil,
+							"previous": nil,
+							"results": []map[string]interface{}{
+								{
+									"guid":              guid,
+									"offer":             "01262626626",
+									"msisdn":            "+254722345678",
+									"deactivation_date": deactivationDate,
+								},
+							},
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				}
+			}
+
+			deactivated := func(_ *http.Request) (*http.Response, error) {
+				resp := silcomms.APIResponse[any]{
+					Status:  silcomms.StatusSuccess,
+					Message: "success",
+					Data: map[string]interface{}{
+						"guid":              guid,
+						"offer":             "01262626626",
+						"msisdn":            "+254722345678",
+						"deactivation_date": "2022-08-04 14:11:17.206377+03:00",
+					},
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, resp)
+			}
+
+			deactivatePath := fmt.Sprintf("%s/v1/sms/subscriptions/%s/deactivate/", config.BaseURL, guid)
+
+			if tt.name == "Happy case: deactivate subscription by guid" {
+				httpmock.RegisterResponder(http.MethodPatch, deactivatePath, deactivated)
+			}
+
+			if tt.name == "Happy case: deactivate subscription by offer and msisdn" {
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), subscriptions(nil))
+				httpmock.RegisterResponder(http.MethodPatch, deactivatePath, deactivated)
+			}
+
+			if tt.name == "Sad case: no active subscription" {
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), subscriptions("2022-08-04 14:11:17.206377+03:00"))
+			}
+
+			if tt.name == "Sad case: invalid status code" {
+				httpmock.RegisterResponder(http.MethodPatch, deactivatePath, func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusNotFound, nil)
+				})
+			}
+
+			got, err := l.DeactivateSubscription(tt.args.ctx, tt.args.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SILCommsLib.DeactivateSubscription() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.name == "Sad case: no active subscription" && !errors.Is(err, silcomms.ErrNotFound) {
+				t.Errorf("SILCommsLib.DeactivateSubscription() error = %v, want %v", err, silcomms.ErrNotFound)
+				return
+			}
+
+			if !tt.wantErr && (got == nil || got.GUID != guid) {
+				t.Errorf("SILCommsLib.DeactivateSubscription() expected the deactivated subscription for %v", tt.name)
+				return
+			}
+		})
+	}
+}
+
+func TestSILCommsLib_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	type args struct {
+		ctx      context.Context
+		offer    string
+		msisdn   string
+		activate bool
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "Happy case: create subscription",
+			args: args{
+				ctx:      ctx,
+				offer:    "01262626626",
+				msisdn:   "+254722345678",
+				activate: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Sad case: invalid status code",
+			args: args{
+				ctx:    ctx,
+				offer:  "01262626626",
+				msisdn: "+254722345678",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: invalid API response",
+			args: args{
+				ctx:    ctx,
+				offer:  "01262626626",
+				msisdn: "+254722345678",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: invalid subscription data response",
+			args: args{
+				ctx:    ctx,
+				offer:  "01262626626",
+				msisdn: "+254722345678",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			l := silcomms.MustNewCommsLib(config, authServer)
+
+			if tt.name == "Happy case: create subscription" {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"guid":            "e602b8b8-9591-4526-915d-57ef2579d8c4",
+							"gateway":         "SAFARICOM",
+							"offer":           "01262626626",
+							"msisdn":          "+254722345678",
+							"link_id":         "123123123123123",
+							"activation_date": "2022-08-04 14:11:17.206377+03:00",
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				})
+			}
+
+			if tt.name == "Sad case: invalid status code" {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+				})
+			}
+
+			if tt.name == "Sad case: invalid API response" {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewStringResponse(http.StatusOK, "not json"), nil
+				})
+			}
+
+			if tt.name == "Sad case: invalid subscription data response" {
+				httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"guid":    123456,
+							"link_id": 123456,
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				})
+			}
+
+			got, err := l.CreateSubscription(tt.args.ctx, tt.args.offer, tt.args.msisdn, tt.args.activate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SILCommsLib.CreateSubscription() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && (got == nil || got.GUID == "" || got.LinkID == "") {
+				t.Errorf("SILCommsLib.CreateSubscription() expected the created subscription for %v, got %v", tt.name, got)
+				return
+			}
+		})
+	}
+}
+
+func TestSILCommsLib_ForEachSubscription(t *testing.T) {
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name      string
+		ctx       func() context.Context
+		fn        func(count *int) func(*silcomms.Subscription) error
+		nextHost  string
+		wantCount int
+		wantErr   error
+	}{
+		{
+			name: "Happy case: iterate over every page",
+			ctx:  context.Background,
+			fn: func(count *int) func(*silcomms.Subscription) error {
+				return func(_ *silcomms.Subscription) error {
+					*count++
+					return nil
+				}
+			},
+			nextHost:  config.BaseURL,
+			wantCount: 5,
+		},
+		{
+			name: "Sad case: callback stops the iteration",
+			ctx:  context.Background,
+			fn: func(count *int) func(*silcomms.Subscription) error {
+				return func(_ *silcomms.Subscription) error {
+					*count++
+					if *count == 3 {
+						return errStop
+					}
+
+					return nil
+				}
+			},
+			nextHost:  config.BaseURL,
+			wantCount: 3,
+			wantErr:   errStop,
+		},
+		{
+			name: "Sad case: cancelled context",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx
+			},
+			fn: func(count *int) func(*silcomms.Subscription) error {
+				return func(_ *silcomms.Subscription) error {
+					*count++
+					return nil
+				}
+			},
+			nextHost:  config.BaseURL,
+			wantCount: 0,
+			wantErr:   context.Canceled,
+		},
+		{
+			name: "Sad case: next cursor points to another host",
+			ctx:  context.Background,
+			fn: func(count *int) func(*silcomms.Subscription) error {
+				return func(_ *silcomms.Subscription) error {
+					*count++
+					return nil
+				}
+			},
+			nextHost:  "https://attacker.example.com",
+			wantCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			l := silcomms.MustNewCommsLib(config, authServer)
+
+			// the responder can outlive a subtest whose context is cancelled so it must not read tt
+			nextHost := tt.nextHost
+
+			pages := map[string][]string{
+				"":  {gofakeit.UUID(), gofakeit.UUID()},
+				"2": {gofakeit.UUID(), gofakeit.UUID()},
+				"3": {gofakeit.UUID()},
+			}
+
+			httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+				page := r.URL.Query().Get("page")
+
+				var next interface{}
+
+				switch page {
+				case "":
+					next = fmt.Sprintf("%s/v1/sms/subscriptions/?offer=01262626626&page=2", nextHost)
+				case "2":
+					next = fmt.Sprintf("%s/v1/sms/subscriptions/?offer=01262626626&page=3", nextHost)
+				}
+
+				results := []map[string]interface{}{}
+				for _, guid := range pages[page] {
+					results = append(results, map[string]interface{}{"guid": guid, "offer": "01262626626"})
+				}
+
+				resp := silcomms.APIResponse[any]{
+					Status:  silcomms.StatusSuccess,
+					Message: "success",
+					Data: map[string]interface{}{
+						"count":    5,
+						"next":     next,
+						"previous": nil,
+						"results":  results,
+					},
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, resp)
+			})
+
+			count := 0
+
+			err := l.ForEachSubscription(tt.ctx(), silcomms.SubscriptionFilter{Offer: "01262626626"}, tt.fn(&count))
+
+			if tt.name == "Sad case: next cursor points to another host" {
+				if err == nil {
+					t.Errorf("SILCommsLib.ForEachSubscription() expected an error for %v", tt.name)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SILCommsLib.ForEachSubscription() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+
+			if count != tt.wantCount {
+				t.Errorf("SILCommsLib.ForEachSubscription() visited %d subscriptions, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestSILCommsLib_ListSubscriptions(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, authServer)
+
+	next := fmt.Sprintf("%s/v1/sms/subscriptions/?page=2", config.BaseURL)
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: map[string]interface{}{
+				"count":    3,
+				"next":     next,
+				"previous": nil,
+				"results": []map[string]interface{}{
+					{"guid": gofakeit.UUID()},
+					{"guid": gofakeit.UUID()},
+				},
+			},
+		}
+
+		if msisdn := r.URL.Query().Get("msisdn"); msisdn != "" && msisdn != "+254712345678" {
+			return httpmock.NewStringResponse(http.StatusBadRequest, "msisdn was not normalized"), nil
+		}
+
+		if r.URL.Query().Get("page") == "2" {
+			resp.Data = map[string]interface{}{
+				"count":    3,
+				"next":     nil,
+				"previous": fmt.Sprintf("%s/v1/sms/subscriptions/", config.BaseURL),
+				"results": []map[string]interface{}{
+					{"guid": gofakeit.UUID()},
+				},
+			}
+		}
+
+		return httpmock.NewJsonResponse(http.StatusOK, resp)
+	})
+
+	if _, err := l.ListSubscriptions(context.Background(), silcomms.SubscriptionFilter{Ordering: "guid"}); err == nil {
+		t.Fatalf("SILCommsLib.ListSubscriptions() expected an error for an invalid filter")
+	}
+
+	if _, err := l.ListSubscriptions(context.Background(), silcomms.SubscriptionFilter{Msisdn: "0712345"}); !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+		t.Fatalf("SILCommsLib.ListSubscriptions() error = %v, want %v", err, silcomms.ErrInvalidMSISDN)
+	}
+
+	if _, err := l.GetSubscriptions(context.Background(), map[string]string{"msisdn": "0712345"}); !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+		t.Fatalf("SILCommsLib.GetSubscriptions() error = %v, want %v", err, silcomms.ErrInvalidMSISDN)
+	}
+
+	if _, err := l.ActivateSubscription(context.Background(), "01262626626", "0712345", true); !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+		t.Fatalf("SILCommsLib.ActivateSubscription() error = %v, want %v", err, silcomms.ErrInvalidMSISDN)
+	}
+
+	if _, err := l.DeactivateSubscription(context.Background(), silcomms.DeactivateSubscriptionInput{Offer: "01262626626", Msisdn: "0712345"}); !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+		t.Fatalf("SILCommsLib.DeactivateSubscription() error = %v, want %v", err, silcomms.ErrInvalidMSISDN)
+	}
+
+	first, err := l.ListSubscriptions(context.Background(), silcomms.SubscriptionFilter{Offer: "01262626626", Msisdn: "0712 345 678", Ordering: "-activation_date"})
+	if err != nil {
+		t.Fatalf("SILCommsLib.ListSubscriptions() error = %v", err)
+	}
+
+	if first.Count != 3 || len(first.Results) != 2 || first.Next == nil || *first.Next != next {
+		t.Fatalf("SILCommsLib.ListSubscriptions() = %+v, want the first page", first)
+	}
+
+	second, err := l.GetSubscriptionsPageAt(context.Background(), *first.Next)
+	if err != nil {
+		t.Fatalf("SILCommsLib.GetSubscriptionsPageAt() error = %v", err)
+	}
+
+	if len(second.Results) != 1 || second.Next != nil || second.Previous == nil {
+		t.Errorf("SILCommsLib.GetSubscriptionsPageAt() = %+v, want the last page", second)
+	}
+}
+
+func TestSILCommsLib_GetBulkSMS(t *testing.T) {
+	ctx := context.Background()
+	guid := gofakeit.UUID()
+
+	type args struct {
+		ctx  context.Context
+		guid string
+	}
+
+	tests := []struct {
+		name         string
+		args         args
+		wantMessages int
+		wantErr      bool
+	}{
+		{
+			name: "Happy case: get bulk sms",
+			args: args{
+				ctx:  ctx,
+				guid: guid,
+			},
+			wantMessages: 2,
+			wantErr:      false,
+		},
+		{
+			name: "Happy case: bulk filter ignored by the API",
+			args: args{
+				ctx:  ctx,
+				guid: guid,
+			},
+			wantMessages: 2,
+			wantErr:      false,
+		},
+		{
+			name: "Happy case: stop listing once every recipient is found",
+			args: args{
+				ctx:  ctx,
+				guid: guid,
+			},
+			wantMessages: 2,
+			wantErr:      false,
+		},
+		{
+			name: "Sad case: missing guid",
+			args: args{
+				ctx: ctx,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: bulk sms not found",
+			args: args{
+				ctx:  ctx,
+				guid: guid,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: invalid bulk SMS data response",
+			args: args{
+				ctx:  ctx,
+				guid: guid,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Sad case: failed to get recipients",
+			args: args{
+				ctx:  ctx,
+				guid: guid,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			l := silcomms.MustNewCommsLib(config, authServer)
+
+			bulkPath := fmt.Sprintf("%s/v1/sms/bulk/%s/", config.BaseURL, guid)
+			smsPath := fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL)
+
+			bulk := func(_ *http.Request) (*http.Response, error) {
+				resp := silcomms.APIResponse[any]{
+					Status:  silcomms.StatusSuccess,
+					Message: "success",
+					Data: map[string]interface{}{
+						"guid":       guid,
+						"sender":     "SIL",
+						"message":    "This is a test",
+						"recipients": []string{"+254722345678", "+254733345678"},
+						"state":      "COMPLETED",
+					},
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, resp)
+			}
+
+			messages := func(r *http.Request) (*http.Response, error) {
+				if r.URL.Query().Get("bulk") != guid {
+					return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+				}
+
+				resp := silcomms.APIResponse[any]{
+					Status:  silcomms.StatusSuccess,
+					Message: "success",
+					Data: map[string]interface{}{
+						"count":    2,
+						"next":     nil,
+						"previous": nil,
+						"results": []map[string]interface{}{
+							{"guid": gofakeit.UUID(), "msisdn": "+254722345678", "state": "DELIVERED", "bulk": guid},
+							{"guid": gofakeit.UUID(), "msisdn": "+254733345678", "state": "FAILED", "bulk": guid},
+						},
+					},
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, resp)
+			}
+
+			if tt.name == "Happy case: get bulk sms" {
+				httpmock.RegisterResponder(http.MethodGet, bulkPath, bulk)
+				httpmock.RegisterResponder(http.MethodGet, smsPath, messages)
+			}
+
+			if tt.name == "Happy case: bulk filter ignored by the API" {
+				httpmock.RegisterResponder(http.MethodGet, bulkPath, bulk)
+				httpmock.RegisterResponder(http.MethodGet, smsPath, func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"count":    3,
+							"next":     nil,
+							"previous": nil,
+							"results": []map[string]interface{}{
+								{"guid": gofakeit.UUID(), "msisdn": "+254722345678", "state": "DELIVERED", "bulk": guid},
+								{"guid": gofakeit.UUID(), "msisdn": "+254744345678", "state": "DELIVERED", "bulk": gofakeit.UUID()},
+								{"guid": gofakeit.UUID(), "msisdn": "+254733345678", "state": "FAILED", "bulk": guid},
+							},
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				})
+			}
+
+			if tt.name == "Happy case: stop listing once every recipient is found" {
+				first, second := gofakeit.UUID(), gofakeit.UUID()
+
+				httpmock.RegisterResponder(http.MethodGet, bulkPath, func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"guid":       guid,
+							"sender":     "SIL",
+							"message":    "This is a test",
+							"recipients": []string{"+254722345678", "+254733345678"},
+							"state":      "COMPLETED",
+							"sms":        []string{first, second},
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				})
+				httpmock.RegisterResponder(http.MethodGet, smsPath, func(r *http.Request) (*http.Response, error) {
+					// the next page is never needed
+					if r.URL.Query().Get("page") == "2" {
+						return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+					}
+
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"count":    4,
+							"next":     fmt.Sprintf("%s?page=2", smsPath),
+							"previous": nil,
+							"results": []map[string]interface{}{
+								{"guid": first, "msisdn": "+254722345678", "state": "DELIVERED", "bulk": guid},
+								{"guid": gofakeit.UUID(), "msisdn": "+254744345678", "state": "DELIVERED", "bulk": guid},
+								{"guid": second, "msisdn": "+254733345678", "state": "FAILED", "bulk": guid},
+							},
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				})
+			}
+
+			if tt.name == "Sad case: bulk sms not found" {
+				httpmock.RegisterResponder(http.MethodGet, bulkPath, func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusNotFound, nil)
+				})
+			}
+
+			if tt.name == "Sad case: invalid bulk SMS data response" {
+				httpmock.RegisterResponder(http.MethodGet, bulkPath, func(_ *http.Request) (*http.Response, error) {
+					resp := silcomms.APIResponse[any]{
+						Status:  silcomms.StatusSuccess,
+						Message: "success",
+						Data: map[string]interface{}{
+							"guid":  123456,
+							"state": 123456,
+						},
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, resp)
+				})
+			}
+
+			if tt.name == "Sad case: failed to get recipients" {
+				httpmock.RegisterResponder(http.MethodGet, bulkPath, bulk)
+				httpmock.RegisterResponder(http.MethodGet, smsPath, func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+				})
+			}
+
+			got, err := l.GetBulkSMS(tt.args.ctx, tt.args.guid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SILCommsLib.GetBulkSMS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.Bulk.GUID != guid || len(got.Messages) != tt.wantMessages {
+				t.Errorf("SILCommsLib.GetBulkSMS() = %+v, want bulk %v with %v messages", got, guid, tt.wantMessages)
+			}
+		})
+	}
+}
+
+func TestSILCommsLib_ListSMS(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, authServer)
+
+	next := fmt.Sprintf("%s/v1/sms/sms/?page=2", config.BaseURL)
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		if r.URL.Query().Get("page") != "2" && r.URL.Query().Get("state") != "DELIVERED" {
+			return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+		}
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: map[string]interface{}{
+				"count":    3,
+				"next":     next,
+				"previous": nil,
+				"results": []map[string]interface{}{
+					{"guid": gofakeit.UUID(), "msisdn": "+254722345678", "state": "DELIVERED"},
+					{"guid": gofakeit.UUID(), "msisdn": "+254733345678", "state": "DELIVERED"},
+				},
+			},
+		}
+
+		if r.URL.Query().Get("page") == "2" {
+			resp.Data = map[string]interface{}{
+				"count":    3,
+				"next":     nil,
+				"previous": fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL),
+				"results": []map[string]interface{}{
+					{"guid": gofakeit.UUID(), "msisdn": "+254744345678", "state": "DELIVERED"},
+				},
+			}
+		}
+
+		return httpmock.NewJsonResponse(http.StatusOK, resp)
+	})
+
+	if _, err := l.ListSMS(context.Background(), silcomms.SMSFilter{Ordering: "body"}); err == nil {
+		t.Fatalf("SILCommsLib.ListSMS() expected an error for an invalid filter")
+	}
+
+	if err := l.ForEachSMS(context.Background(), silcomms.SMSFilter{Ordering: "body"}, func(*silcomms.SMS) error { return nil }); err == nil {
+		t.Fatalf("SILCommsLib.ForEachSMS() expected an error for an invalid filter")
+	}
+
+	filter := silcomms.SMSFilter{State: "DELIVERED", Ordering: "-created"}
+
+	first, err := l.ListSMS(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("SILCommsLib.ListSMS() error = %v", err)
+	}
+
+	if first.Count != 3 || len(first.Results) != 2 || first.Next == nil || *first.Next != next {
+		t.Fatalf("SILCommsLib.ListSMS() = %+v, want the first page", first)
+	}
+
+	second, err := l.GetSMSPageAt(context.Background(), *first.Next)
+	if err != nil {
+		t.Fatalf("SILCommsLib.GetSMSPageAt() error = %v", err)
+	}
+
+	if len(second.Results) != 1 || second.Next != nil || second.Previous == nil {
+		t.Errorf("SILCommsLib.GetSMSPageAt() = %+v, want the last page", second)
+	}
+
+	msisdns := []string{}
+
+	err = l.ForEachSMS(context.Background(), filter, func(sms *silcomms.SMS) error {
+		msisdns = append(msisdns, sms.Msisdn)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SILCommsLib.ForEachSMS() error = %v", err)
+	}
+
+	if len(msisdns) != 3 {
+		t.Errorf("SILCommsLib.ForEachSMS() visited %v, want 3 SMS", msisdns)
+	}
+}
+
+func TestSILCommsLib_ListInboundSMS(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, authServer)
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/v1/sms/sms/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		if r.URL.Query().Get("direction") != "INBOUND" {
+			return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+		}
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: map[string]interface{}{
+				"count":    1,
+				"next":     nil,
+				"previous": nil,
+				"results": []map[string]interface{}{
+					{"guid": gofakeit.UUID(), "body": "YES", "msisdn": "+254722345678", "link_id": "123456", "direction": "INBOUND"},
+				},
+			},
+		}
+
+		return httpmock.NewJsonResponse(http.StatusOK, resp)
+	})
+
+	if _, err := l.ListInboundSMS(context.Background(), silcomms.SMSFilter{Ordering: "body"}); err == nil {
+		t.Fatalf("SILCommsLib.ListInboundSMS() expected an error for an invalid filter")
+	}
+
+	got, err := l.ListInboundSMS(context.Background(), silcomms.SMSFilter{Direction: "OUTBOUND", Msisdn: "+254722345678"})
+	if err != nil {
+		t.Fatalf("SILCommsLib.ListInboundSMS() error = %v", err)
+	}
+
+	if len(got.Results) != 1 || got.Results[0].LinkID != "123456" || got.HasNext() {
+		t.Errorf("SILCommsLib.ListInboundSMS() = %+v, want a single inbound sms", got)
+	}
+}
+
+func TestCommsLib_SendBulkSMSNormalizesRecipients(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, authServer)
+
+	want := []string{"+254712345678", "+254722345678", "+254110345678"}
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		var payload struct {
+			Recipients []string `json:"recipients"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || !reflect.DeepEqual(payload.Recipients, want) {
+			return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+		}
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: map[string]interface{}{
+				"guid":       gofakeit.UUID(),
+				"recipients": payload.Recipients,
+			},
+		}
+
+		return httpmock.NewJsonResponse(http.StatusAccepted, resp)
+	})
+
+	recipients := []string{"0712 345 678", "+254-722-345-678", "110345678"}
+
+	if _, err := l.SendBulkSMS(context.Background(), "This is a test", recipients, ""); err != nil {
+		t.Errorf("SILCommsLib.SendBulkSMS() error = %v, want the recipients sent as %v", err, want)
+	}
+}
+
+func TestCommsLib_SendBulkSMSRecipientPreflight(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	l := silcomms.MustNewCommsLib(config, authServer)
+
+	want := []string{"+254712345678", "+254733345678"}
+
+	httpmock.RegisterResponder(http.MethodPost, fmt.Sprintf("%s/v1/sms/bulk/", config.BaseURL), func(r *http.Request) (*http.Response, error) {
+		var payload struct {
+			Recipients []string `json:"recipients"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || !reflect.DeepEqual(payload.Recipients, want) {
+			return httpmock.NewJsonResponse(http.StatusBadRequest, nil)
+		}
+
+		resp := silcomms.APIResponse[any]{
+			Status:  silcomms.StatusSuccess,
+			Message: "success",
+			Data: map[string]interface{}{
+				"guid":       gofakeit.UUID(),
+				"recipients": payload.Recipients,
+			},
+		}
+
+		return httpmock.NewJsonResponse(http.StatusAccepted, resp)
+	})
+
+	recipients := []string{"0712 345 678", "N/A", "+254712345678", "0733345678", "0712"}
+
+	if _, err := l.SendBulkSMS(context.Background(), "This is a test", recipients, ""); !errors.Is(err, silcomms.ErrInvalidMSISDN) {
+		t.Fatalf("SILCommsLib.SendBulkSMS() error = %v, want %v without the pre-flight step", err, silcomms.ErrInvalidMSISDN)
+	}
+
+	var report silcomms.RecipientReport
+
+	if _, err := l.SendBulkSMS(context.Background(), "This is a test", recipients, "", silcomms.WithRecipientPreflight(&report)); err != nil {
+		t.Fatalf("SILCommsLib.SendBulkSMS() error = %v, want the valid recipients sent as %v", err, want)
+	}
+
+	if len(report.Valid) != 2 || len(report.Duplicates) != 1 || len(report.Invalid) != 2 {
+		t.Errorf("SILCommsLib.SendBulkSMS() report = %+v, want 2 valid, 1 duplicate and 2 invalid recipients", report)
+	}
+
+	var empty silcomms.RecipientReport
+
+	_, err := l.SendBulkSMS(context.Background(), "This is a test", []string{"N/A", "0712"}, "", silcomms.WithRecipientPreflight(&empty))
+	if !errors.Is(err, silcomms.ErrInvalidMSISDN) || len(empty.Invalid) != 2 {
+		t.Errorf("SILCommsLib.SendBulkSMS() error = %v, report = %+v, want both recipients reported as invalid", err, empty)
+	}
+
+	if _, err := l.SendBulkSMS(context.Background(), "This is a test", nil, "", silcomms.WithRecipientPreflight(nil)); err == nil {
+		t.Errorf("SILCommsLib.SendBulkSMS() expected an error without recipients")
+	}
+}